@@ -0,0 +1,174 @@
+package container
+
+import (
+	"context"
+	"fmt"
+)
+
+// FeedError - ошибка FeedFrom, прерванного отменой контекста или закрытием очереди,
+// когда элемент уже прочитан из канала, но ещё не добавлен в очередь: элемент
+// возвращается вызывающему (errors.As), а errors.Is сравнивает с причиной
+type FeedError[T any] struct {
+	Item T     // Прочитанный из канала, но не добавленный элемент
+	Err  error // Причина: ctx.Err() или ErrClosed
+}
+
+func (e *FeedError[T]) Error() string {
+	return fmt.Sprintf("feed interrupted with pending item: %v", e.Err)
+}
+func (e *FeedError[T]) Unwrap() error {
+	return e.Err
+}
+
+// region - deque
+
+// FeedFrom - перекладывает элементы из канала в очередь (с указанной стороны), пока канал
+// не будет закрыт (возвращает nil), не будет отменён контекст (возвращает ctx.Err())
+// или не будет закрыта очередь (возвращает ErrClosed). Пока ограниченная очередь
+// без вытеснения заполнена, чтение из канала приостанавливается. Если к этому моменту
+// элемент уже прочитан из канала, но не добавлен в очередь, возвращается *FeedError
+// с этим элементом (обработчик вытеснения и Observer о нём не узнают).
+// Блокирует вызывающего до завершения.
+func (s *Deque[T]) FeedFrom(ctx context.Context, in <-chan T, side Side) error {
+	push := s.pusher(side)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := s.pushWait(ctx, item, push); err != nil {
+				return &FeedError[T]{Item: item, Err: err}
+			}
+		}
+	}
+}
+
+// Drain - возвращает канал, в который передаются элементы, извлекаемые из очереди
+// (с указанной стороны) по мере их появления. Канал закрывается при отмене контекста
+// или когда закрытая очередь опустеет. Элемент, извлечённый, но не переданный
+// в канал из-за отмены контекста, возвращается на прежнее место в очереди.
+func (s *Deque[T]) Drain(ctx context.Context, side Side) <-chan T {
+	out := make(chan T)
+	pop := s.popper(side)
+	go func() {
+		defer close(out)
+		for {
+			item, err := s.popWait(ctx, pop)
+			if err != nil {
+				return
+			}
+			select {
+			case out <- item:
+			case <-ctx.Done():
+				s.restore(item, side)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// endregion
+// region - concurrent deque
+
+// FeedFrom - перекладывает элементы из канала в очередь; семантика такая же,
+// как у Deque.FeedFrom
+func (s *ConcurrentDeque[T]) FeedFrom(ctx context.Context, in <-chan T, side Side) error {
+	push := s.PushTail
+	if side == SideHead {
+		push = s.PushHead
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := s.pushWait(ctx, func() error { return push(item) }); err != nil {
+				return &FeedError[T]{Item: item, Err: err}
+			}
+		}
+	}
+}
+
+// Drain - возвращает канал, в который передаются элементы, извлекаемые из очереди;
+// семантика такая же, как у Deque.Drain
+func (s *ConcurrentDeque[T]) Drain(ctx context.Context, side Side) <-chan T {
+	out := make(chan T)
+	pop := s.PopTail
+	if side == SideHead {
+		pop = s.PopHead
+	}
+	go func() {
+		defer close(out)
+		for {
+			item, err := s.popWait(ctx, pop)
+			if err != nil {
+				return
+			}
+			select {
+			case out <- item:
+			case <-ctx.Done():
+				s.restore(item, side)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// endregion
+// region - ring buffer
+
+// FeedFrom - перекладывает элементы из канала в буфер, пока канал не будет закрыт
+// (возвращает nil), не будет отменён контекст (возвращает ctx.Err()) или не будет
+// закрыт буфер (возвращает *FeedError с прочитанным, но не записанным элементом;
+// errors.Is(err, ErrClosed)). Запись в буфер не блокируется (старые элементы
+// перезаписываются), поэтому чтение из канала не приостанавливается.
+// Блокирует вызывающего до завершения.
+func (rb *RingBuffer[T]) FeedFrom(ctx context.Context, in <-chan T) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := rb.TryPush(item); err != nil {
+				return &FeedError[T]{Item: item, Err: err}
+			}
+		}
+	}
+}
+
+// Drain - возвращает канал, в который передаются элементы, извлекаемые из буфера
+// (в порядке Pop) по мере их появления. Канал закрывается при отмене контекста или
+// когда закрытый буфер опустеет. Элемент, извлечённый, но не переданный в канал
+// из-за отмены контекста, возвращается в буфер, если в нём осталось место.
+func (rb *RingBuffer[T]) Drain(ctx context.Context) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			item, seq, err := rb.popWait(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case out <- item:
+			case <-ctx.Done():
+				rb.restore(item, seq)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// endregion
@@ -0,0 +1,174 @@
+package container
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDequeFeedFrom(t *testing.T) {
+
+	d := NewDeque[int](
+		DequeWithSizeLimit(2),
+	)
+
+	in := make(chan int)
+	fed := make(chan error)
+	go func() {
+		fed <- d.FeedFrom(context.Background(), in, SideTail)
+	}()
+
+	in <- 1
+	in <- 2
+	in <- 3 // прочитан из канала, но ждёт места в очереди
+
+	// очередь заполнена - канал не читается
+	select {
+	case in <- 4:
+		assert.Fail(t, "feeder must not read from channel while deque is full")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	v, err := d.PopHead()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	in <- 4 // 3 добавлен в очередь, 4 ждёт места
+	v, err = d.PopHead()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	close(in)
+	assert.NoError(t, <-fed)
+	assert.Equal(t, []int{3, 4}, d.Values())
+
+}
+func TestDequeFeedFromCancel(t *testing.T) {
+
+	var evicted []int
+	c := NewDequeCounter()
+	d := NewDeque[int](
+		DequeWithSizeLimit(1),
+		DequeWithEvictionHandler(func(item int, side Side) {
+			evicted = append(evicted, item)
+		}),
+		DequeWithObserver(c),
+	)
+
+	in := make(chan int, 2)
+	in <- 1
+	in <- 2
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := d.FeedFrom(ctx, in, SideHead)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []int{1}, d.Values())
+
+	// недобавленный элемент возвращается в ошибке, а не считается вытесненным
+	var fe *FeedError[int]
+	if assert.ErrorAs(t, err, &fe) {
+		assert.Equal(t, 2, fe.Item)
+	}
+	assert.Empty(t, evicted)
+	assert.Equal(t, int64(0), c.Stats().Evictions)
+
+}
+func TestConcurrentDequeFeedFromClosed(t *testing.T) {
+
+	c := NewDequeCounter()
+	d := NewConcurrentDeque[int](DequeWithSizeLimit(1), DequeWithObserver(c))
+	assert.NoError(t, d.PushTail(1))
+
+	in := make(chan int, 1)
+	in <- 2
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		d.Close()
+	}()
+
+	err := d.FeedFrom(context.Background(), in, SideTail)
+	assert.ErrorIs(t, err, ErrClosed)
+	var fe *FeedError[int]
+	if assert.ErrorAs(t, err, &fe) {
+		assert.Equal(t, 2, fe.Item)
+	}
+	assert.Equal(t, []int{1}, d.Values())
+	assert.Equal(t, int64(0), c.Stats().Evictions)
+
+}
+func TestDequeDrain(t *testing.T) {
+
+	d := NewDeque[int]()
+	assert.NoError(t, d.PushTailAll(1, 2, 3))
+
+	out := d.Drain(context.Background(), SideHead)
+	assert.Equal(t, 1, <-out)
+	assert.Equal(t, 2, <-out)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, d.PushTail(4))
+		d.Close()
+	}()
+
+	var rest []int
+	for v := range out {
+		rest = append(rest, v)
+	}
+	assert.Equal(t, []int{3, 4}, rest)
+
+}
+func TestDequeDrainCancel(t *testing.T) {
+
+	d := NewDeque[int]()
+	assert.NoError(t, d.PushTailAll(1, 2, 3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := d.Drain(ctx, SideTail)
+	assert.Equal(t, 3, <-out)
+
+	// следующий элемент извлечён, но не прочитан из канала
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	_, ok := <-out
+	for ok {
+		_, ok = <-out
+	}
+	assert.Equal(t, []int{1, 2}, d.Values())
+
+}
+func TestRingBufferChannels(t *testing.T) {
+
+	rb := NewRingBuffer[int](3)
+
+	in := make(chan int)
+	fed := make(chan error)
+	go func() {
+		fed <- rb.FeedFrom(context.Background(), in)
+	}()
+	in <- 1
+	in <- 2
+	close(in)
+	assert.NoError(t, <-fed)
+	assert.Equal(t, 2, rb.Len())
+
+	out := rb.Drain(context.Background())
+	assert.Equal(t, 2, <-out)
+	assert.Equal(t, 1, <-out)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		rb.Push(3)
+		rb.Close()
+	}()
+
+	var rest []int
+	for v := range out {
+		rest = append(rest, v)
+	}
+	assert.Equal(t, []int{3}, rest)
+
+}
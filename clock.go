@@ -0,0 +1,142 @@
+package container
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock - источник времени для очередей, работающих по расписанию (DelayQueue);
+// в тестах вместо системного времени можно использовать ManualClock
+type Clock interface {
+	Now() time.Time
+	// NewTimer - запускает таймер, который отправит время в свой канал по прошествии d
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer - таймер, запущенный Clock.NewTimer
+type Timer interface {
+	// C - возвращает канал, в который отправляется время срабатывания таймера
+	C() <-chan time.Time
+	// Stop - останавливает таймер; возвращает false, если таймер уже сработал
+	// или был остановлен
+	Stop() bool
+}
+
+type systemClock struct{}
+
+// SystemClock - Clock на основе пакета time
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+type manualTimer struct {
+	clock *ManualClock
+	at    time.Time
+	c     chan time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+func (t *manualTimer) Stop() bool {
+	c := t.clock
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = slices.Delete(c.timers, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// ManualClock - Clock, время которого меняется только вызовами Set/Advance;
+// безопасен для конкурентного использования
+type ManualClock struct {
+	now    time.Time
+	timers []*manualTimer
+	mutex  sync.Mutex
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{
+		now: now,
+	}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+func (c *ManualClock) NewTimer(d time.Duration) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	t := &manualTimer{
+		clock: c,
+		at:    c.now.Add(d),
+		c:     make(chan time.Time, 1),
+	}
+	if d <= 0 {
+		t.c <- c.now
+	} else {
+		c.timers = append(c.timers, t)
+	}
+	return t
+}
+
+// Advance - переводит часы вперёд на d, срабатывают наступившие таймеры
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set - устанавливает время часов, срабатывают наступившие таймеры
+func (c *ManualClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.set(now)
+}
+
+// Timers - количество запущенных, но ещё не сработавших и не остановленных таймеров
+// (позволяет тесту дождаться, пока ожидающая горутина запустит таймер)
+func (c *ManualClock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+func (c *ManualClock) set(now time.Time) {
+	c.now = now
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- now
+	}
+	clear(c.timers[len(pending):])
+	c.timers = pending
+}
@@ -0,0 +1,1062 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrentDeque - вариант Deque с раздельными блокировками головы и хвоста.
+//
+// Очередь хранится в двух стеках: головном (вершина - голова очереди) и хвостовом
+// (вершина - хвост очереди). Операции с головой захватывают только блокировку головы,
+// с хвостом - только блокировку хвоста, поэтому производители, добавляющие в хвост,
+// не конкурируют с потребителями, извлекающими из головы. Обе блокировки (всегда
+// в порядке голова -> хвост) захватываются, только когда стек нужной стороны пуст
+// и половину элементов нужно перенести из другого стека (амортизированно O(1)),
+// при вытеснении и в операциях над всей очередью (Values, индексный доступ,
+// пакетные операции, Expand/Shrink и т.п.).
+//
+// Набор методов и их семантика совпадают с Deque.
+type ConcurrentDeque[T any] struct {
+	headMutex sync.Mutex
+	head      []T // Головной стек: последний элемент - голова очереди
+	_         [64]byte
+
+	tailMutex sync.Mutex
+	tail      []T // Хвостовой стек: последний элемент - хвост очереди
+	_         [64]byte
+
+	size            atomic.Int64 // Количество элементов (включая добавляемые в данный момент)
+	initialCapacity int          // Начальная ёмкость
+	capacity        int          // Максимальный размер очереди (-1 для безлимитной очереди); меняется под обеими блокировками
+	sizeLimit       int          // Заданный опцией максимальный размер (нижняя граница при автоматическом сжатии)
+	preemption      bool         // Вытеснять крайний элемент с противоположной стороны при добавлении нового в заполненную очередь
+	onEvict         func(T, Side)
+	policy          GrowthPolicy // Политика изменения максимального размера
+	autoResize      bool         // Менять максимальный размер при добавлении и извлечении элементов
+	maxSize         int          // Предел максимального размера при автоматическом расширении
+	observer        Observer     // Получатель событий очереди
+
+	closed   atomic.Bool   // Очередь закрыта для добавления элементов; меняется под обеими блокировками
+	done     chan struct{} // Закрывается, когда очередь закрыта и пуста
+	doneOnce sync.Once
+
+	waiters     atomic.Int32  // Количество ожидающих в блокирующих операциях
+	signal      chan struct{} // Закрывается при изменении содержимого очереди
+	signalMutex sync.Mutex
+}
+
+func NewConcurrentDeque[T any](opts ...DequeOption) *ConcurrentDeque[T] {
+
+	qc := newQueueConfig(opts...)
+
+	initialCapacity := 8
+	if qc.sizeLimit > 0 {
+		initialCapacity = qc.sizeLimit
+	}
+
+	return &ConcurrentDeque[T]{
+		initialCapacity: initialCapacity,
+		capacity:        qc.sizeLimit,
+		sizeLimit:       qc.sizeLimit,
+		preemption:      qc.preemption,
+		onEvict:         evictionHandler[T](qc),
+		policy:          qc.growthPolicy,
+		autoResize:      qc.autoResize,
+		maxSize:         qc.maxSize,
+		observer:        qc.observer,
+		done:            make(chan struct{}),
+	}
+
+}
+
+// ConcurrentDequeFrom - создаёт очередь и заполняет её (с хвоста) элементами из
+// последовательности; в заполненную очередь без вытеснения лишние элементы не добавляются
+func ConcurrentDequeFrom[T any](seq iter.Seq[T], opts ...DequeOption) *ConcurrentDeque[T] {
+	d := NewConcurrentDeque[T](opts...)
+	for item := range seq {
+		if d.PushTail(item) != nil {
+			break
+		}
+	}
+	return d
+}
+
+// region - lifecycle
+
+// Close - закрывает очередь; семантика такая же, как у Deque.Close()
+func (s *ConcurrentDeque[T]) Close() {
+	s.lockBoth()
+	s.closed.Store(true)
+	s.unlockBoth()
+	s.notify()
+}
+
+// Closed - возвращает true, если очередь закрыта
+func (s *ConcurrentDeque[T]) Closed() bool {
+	return s.closed.Load()
+}
+
+// Done - возвращает канал, который закрывается, когда очередь закрыта и все
+// элементы из неё извлечены
+func (s *ConcurrentDeque[T]) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *ConcurrentDeque[T]) Flush() {
+	s.lockBoth()
+	s.flush()
+	s.unlockBoth()
+	s.notify()
+}
+func (s *ConcurrentDeque[T]) flush() {
+	s.observeClear()
+	if s.autoResize {
+		// автоматически расширенная очередь возвращается к заданному ограничению размера
+		s.initialCapacity = s.sizeLimit
+	}
+	s.head = nil
+	s.tail = nil
+	s.size.Store(0)
+	if s.capacity > 0 {
+		oldCapacity := s.capacity
+		s.capacity = s.initialCapacity
+		s.observeResize(oldCapacity)
+	}
+}
+
+func (s *ConcurrentDeque[T]) Replace(items ...T) (err error) {
+	s.lockBoth()
+	if s.closed.Load() {
+		s.unlockBoth()
+		return ErrClosed
+	}
+	s.flush()
+	err = s.processAll(true, SideHead, items...)
+	s.unlockBoth()
+	s.notify()
+	return err
+}
+
+// endregion
+// region - head
+
+func (s *ConcurrentDeque[T]) PushHeadAll(items ...T) error {
+	return s.pushAll(false, SideHead, items...)
+}
+func (s *ConcurrentDeque[T]) PushHeadAllReversed(items ...T) error {
+	return s.pushAll(true, SideHead, items...)
+}
+func (s *ConcurrentDeque[T]) PushHead(item T) error {
+
+	s.headMutex.Lock()
+	if s.closed.Load() {
+		s.headMutex.Unlock()
+		return ErrClosed
+	}
+	if !s.growNeeded() && s.reserve() {
+		s.head = append(s.head, item)
+		s.observePush(SideHead)
+		s.headMutex.Unlock()
+		s.notify()
+		return nil
+	}
+	full := !s.preemption && !s.autoResize
+	if full {
+		s.reject(SideHead)
+	}
+	s.headMutex.Unlock()
+
+	// очередь заполнена: вытеснение и расширение требуют обеих блокировок
+	if full {
+		return ErrDequeueFull
+	}
+	s.lockBoth()
+	err := s.pushLocked(SideHead, item)
+	s.unlockBoth()
+	s.notify()
+	return err
+
+}
+func (s *ConcurrentDeque[T]) PushHeadWait(ctx context.Context, item T) error {
+	return s.pushWait(ctx, func() error { return s.PushHead(item) })
+}
+func (s *ConcurrentDeque[T]) PopHead() (T, error) {
+
+	s.headMutex.Lock()
+	if n := len(s.head); n > 0 {
+		item := pop(&s.head)
+		s.size.Add(-1)
+		s.observePop(SideHead)
+		shrink := s.shrinkNeeded()
+		s.headMutex.Unlock()
+		if shrink {
+			s.lockBoth()
+			s.autoShrink()
+			s.unlockBoth()
+		}
+		s.notify()
+		return item, nil
+	}
+
+	// головной стек пуст - переносим часть элементов из хвостового
+	s.tailMutex.Lock()
+	item, ok := s.popLocked(SideHead)
+	if ok {
+		s.observePop(SideHead)
+		s.autoShrink()
+	}
+	s.unlockBoth()
+	if !ok {
+		return item, ErrDequeueEmpty
+	}
+	s.notify()
+	return item, nil
+
+}
+func (s *ConcurrentDeque[T]) PopHeadWait(ctx context.Context) (T, error) {
+	return s.popWait(ctx, s.PopHead)
+}
+func (s *ConcurrentDeque[T]) PeekHead() (T, error) {
+	s.headMutex.Lock()
+	defer s.headMutex.Unlock()
+	if n := len(s.head); n > 0 {
+		return s.head[n-1], nil
+	}
+	s.tailMutex.Lock()
+	defer s.tailMutex.Unlock()
+	if len(s.tail) > 0 {
+		return s.tail[0], nil
+	}
+	var empty T
+	return empty, ErrDequeueEmpty
+}
+
+// endregion
+// region - tail
+
+func (s *ConcurrentDeque[T]) PushTailAll(items ...T) error {
+	return s.pushAll(false, SideTail, items...)
+}
+func (s *ConcurrentDeque[T]) PushTailAllReversed(items ...T) error {
+	return s.pushAll(true, SideTail, items...)
+}
+func (s *ConcurrentDeque[T]) PushTail(item T) error {
+
+	s.tailMutex.Lock()
+	if s.closed.Load() {
+		s.tailMutex.Unlock()
+		return ErrClosed
+	}
+	if !s.growNeeded() && s.reserve() {
+		s.tail = append(s.tail, item)
+		s.observePush(SideTail)
+		s.tailMutex.Unlock()
+		s.notify()
+		return nil
+	}
+	full := !s.preemption && !s.autoResize
+	if full {
+		s.reject(SideTail)
+	}
+	s.tailMutex.Unlock()
+
+	// очередь заполнена: вытеснение и расширение требуют обеих блокировок
+	if full {
+		return ErrDequeueFull
+	}
+	s.lockBoth()
+	err := s.pushLocked(SideTail, item)
+	s.unlockBoth()
+	s.notify()
+	return err
+
+}
+func (s *ConcurrentDeque[T]) PushTailWait(ctx context.Context, item T) error {
+	return s.pushWait(ctx, func() error { return s.PushTail(item) })
+}
+func (s *ConcurrentDeque[T]) PopTail() (T, error) {
+
+	s.tailMutex.Lock()
+	if n := len(s.tail); n > 0 {
+		item := pop(&s.tail)
+		s.size.Add(-1)
+		s.observePop(SideTail)
+		shrink := s.shrinkNeeded()
+		s.tailMutex.Unlock()
+		if shrink {
+			s.lockBoth()
+			s.autoShrink()
+			s.unlockBoth()
+		}
+		s.notify()
+		return item, nil
+	}
+	s.tailMutex.Unlock()
+
+	// хвостовой стек пуст - переносим часть элементов из головного
+	// (блокировки захватываются заново в порядке голова -> хвост)
+	s.lockBoth()
+	item, ok := s.popLocked(SideTail)
+	if ok {
+		s.observePop(SideTail)
+		s.autoShrink()
+	}
+	s.unlockBoth()
+	if !ok {
+		return item, ErrDequeueEmpty
+	}
+	s.notify()
+	return item, nil
+
+}
+func (s *ConcurrentDeque[T]) PopTailWait(ctx context.Context) (T, error) {
+	return s.popWait(ctx, s.PopTail)
+}
+func (s *ConcurrentDeque[T]) PeekTail() (T, error) {
+	s.tailMutex.Lock()
+	if n := len(s.tail); n > 0 {
+		item := s.tail[n-1]
+		s.tailMutex.Unlock()
+		return item, nil
+	}
+	s.tailMutex.Unlock()
+
+	s.lockBoth()
+	defer s.unlockBoth()
+	if n := len(s.tail); n > 0 {
+		return s.tail[n-1], nil
+	}
+	if len(s.head) > 0 {
+		return s.head[0], nil
+	}
+	var empty T
+	return empty, ErrDequeueEmpty
+}
+
+// endregion
+// region - either side
+
+// Push - добавляет элементы с заданной стороны очереди (как PushHeadAll/PushTailAll);
+// при вытеснении из заполненной очереди удаляется элемент с противоположной стороны
+func (s *ConcurrentDeque[T]) Push(side Side, items ...T) error {
+	if side == SideHead {
+		return s.PushHeadAll(items...)
+	}
+	return s.PushTailAll(items...)
+}
+
+// PushWait - добавляет элемент с заданной стороны очереди, ожидая освобождения места
+// (как PushHeadWait/PushTailWait)
+func (s *ConcurrentDeque[T]) PushWait(ctx context.Context, side Side, item T) error {
+	if side == SideHead {
+		return s.PushHeadWait(ctx, item)
+	}
+	return s.PushTailWait(ctx, item)
+}
+
+// Pop - извлекает элемент с заданной стороны очереди
+func (s *ConcurrentDeque[T]) Pop(side Side) (T, error) {
+	if side == SideHead {
+		return s.PopHead()
+	}
+	return s.PopTail()
+}
+
+// PopWait - извлекает элемент с заданной стороны очереди, ожидая его появления
+// (как PopHeadWait/PopTailWait)
+func (s *ConcurrentDeque[T]) PopWait(ctx context.Context, side Side) (T, error) {
+	if side == SideHead {
+		return s.PopHeadWait(ctx)
+	}
+	return s.PopTailWait(ctx)
+}
+
+// Peek - возвращает элемент с заданной стороны очереди, не извлекая его
+func (s *ConcurrentDeque[T]) Peek(side Side) (T, error) {
+	if side == SideHead {
+		return s.PeekHead()
+	}
+	return s.PeekTail()
+}
+
+// endregion
+// region - whole deque
+
+func (s *ConcurrentDeque[T]) Expand() (err error) {
+	s.lockBoth()
+	if s.capacity <= 0 {
+		s.unlockBoth()
+		return // для unbounded deque ничего не делаем
+	}
+	if c := s.policy.Grow(int(s.size.Load()), s.capacity); c > s.capacity {
+		err = s.resize(c)
+	}
+	s.unlockBoth()
+	s.notify()
+	return
+}
+func (s *ConcurrentDeque[T]) Shrink() (err error) {
+	s.lockBoth()
+	if s.capacity <= 0 {
+		s.unlockBoth()
+		return // для unbounded deque ничего не делаем
+	}
+	if c := max(1, s.policy.Shrink(int(s.size.Load()), s.capacity)); c < s.capacity {
+		err = s.resize(c)
+	}
+	s.unlockBoth()
+	s.notify()
+	return
+}
+
+// Values - возвращает массив элементов в очереди
+func (s *ConcurrentDeque[T]) Values() []T {
+	s.lockBoth()
+	defer s.unlockBoth()
+	return s.values()
+}
+
+// All - возвращает итератор по элементам очереди от головы к хвосту. Перебор выполняется
+// "вживую" под обеими блокировками очереди: тело цикла не должно обращаться к очереди,
+// а все остальные операции ждут завершения перебора.
+func (s *ConcurrentDeque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.lockBoth()
+		defer s.unlockBoth()
+		for i := len(s.head) - 1; i >= 0; i-- {
+			if !yield(s.head[i]) {
+				return
+			}
+		}
+		for _, item := range s.tail {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Backward - возвращает итератор по элементам очереди от хвоста к голове;
+// семантика блокировки такая же, как у All()
+func (s *ConcurrentDeque[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.lockBoth()
+		defer s.unlockBoth()
+		for i := len(s.tail) - 1; i >= 0; i-- {
+			if !yield(s.tail[i]) {
+				return
+			}
+		}
+		for _, item := range s.head {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Size - возвращает количество элементов в очереди
+func (s *ConcurrentDeque[T]) Size() int {
+	return int(s.size.Load())
+}
+
+// Capacity - возвращает максимальный размер очереди; -1 для неограниченной очереди
+func (s *ConcurrentDeque[T]) Capacity() int {
+	s.headMutex.Lock()
+	defer s.headMutex.Unlock()
+	return s.capacity
+}
+
+// endregion
+// region - batch operations
+
+func (s *ConcurrentDeque[T]) PopHeadN(n int) []T {
+	return s.popWhile(SideHead, func(i int, _ T) bool { return i < n })
+}
+func (s *ConcurrentDeque[T]) PopTailN(n int) []T {
+	return s.popWhile(SideTail, func(i int, _ T) bool { return i < n })
+}
+func (s *ConcurrentDeque[T]) PopHeadWhile(pred func(T) bool) []T {
+	return s.popWhile(SideHead, func(_ int, item T) bool { return pred(item) })
+}
+func (s *ConcurrentDeque[T]) PopTailWhile(pred func(T) bool) []T {
+	return s.popWhile(SideTail, func(_ int, item T) bool { return pred(item) })
+}
+func (s *ConcurrentDeque[T]) DrainAll() []T {
+	s.lockBoth()
+	v := s.values()
+	s.flush()
+	s.unlockBoth()
+	s.notify()
+	return v
+}
+
+// endregion
+// region - indexed access
+
+func (s *ConcurrentDeque[T]) At(i int) (T, error) {
+	s.lockBoth()
+	defer s.unlockBoth()
+	if p := s.at(i); p != nil {
+		return *p, nil
+	}
+	var empty T
+	return empty, ErrOutOfRange
+}
+func (s *ConcurrentDeque[T]) Set(i int, item T) error {
+	s.lockBoth()
+	defer s.unlockBoth()
+	if p := s.at(i); p != nil {
+		*p = item
+		return nil
+	}
+	return ErrOutOfRange
+}
+func (s *ConcurrentDeque[T]) InsertAt(i int, item T) error {
+	s.lockBoth()
+	err := s.insertAt(i, item)
+	s.unlockBoth()
+	s.notify()
+	return err
+}
+func (s *ConcurrentDeque[T]) RemoveAt(i int) (T, error) {
+	s.lockBoth()
+	if i < 0 || i >= len(s.head)+len(s.tail) {
+		s.unlockBoth()
+		var empty T
+		return empty, ErrOutOfRange
+	}
+	side := s.nearestSide(i)
+	var item T
+	if i < len(s.head) {
+		j := len(s.head) - 1 - i
+		item = s.head[j]
+		s.head = slices.Delete(s.head, j, j+1)
+	} else {
+		j := i - len(s.head)
+		item = s.tail[j]
+		s.tail = slices.Delete(s.tail, j, j+1)
+	}
+	s.size.Add(-1)
+	s.observePop(side)
+	s.autoShrink()
+	s.unlockBoth()
+	s.notify()
+	return item, nil
+}
+func (s *ConcurrentDeque[T]) Swap(i, j int) error {
+	s.lockBoth()
+	defer s.unlockBoth()
+	x, y := s.at(i), s.at(j)
+	if x == nil || y == nil {
+		return ErrOutOfRange
+	}
+	*x, *y = *y, *x
+	return nil
+}
+
+// endregion
+// region - reordering and search
+
+func (s *ConcurrentDeque[T]) Rotate(n int) {
+	s.lockBoth()
+	defer s.unlockBoth()
+	count := len(s.head) + len(s.tail)
+	if count < 2 {
+		return
+	}
+	k := (n%count + count) % count
+	if k == 0 {
+		return
+	}
+	v := s.values()
+	s.head = nil
+	s.tail = append(v[count-k:], v[:count-k]...)
+}
+
+// Reverse - меняет порядок элементов на обратный: головной и хвостовой стеки
+// достаточно поменять местами
+func (s *ConcurrentDeque[T]) Reverse() {
+	s.lockBoth()
+	s.head, s.tail = s.tail, s.head
+	s.unlockBoth()
+}
+func (s *ConcurrentDeque[T]) IndexFunc(pred func(T) bool) int {
+	s.lockBoth()
+	defer s.unlockBoth()
+	for i := range len(s.head) + len(s.tail) {
+		if pred(*s.at(i)) {
+			return i
+		}
+	}
+	return -1
+}
+func (s *ConcurrentDeque[T]) ContainsFunc(pred func(T) bool) bool {
+	return s.IndexFunc(pred) >= 0
+}
+func (s *ConcurrentDeque[T]) RemoveFunc(pred func(T) bool) int {
+	s.lockBoth()
+	n := s.removeFunc(pred)
+	s.unlockBoth()
+	s.notify()
+	return n
+}
+func (s *ConcurrentDeque[T]) RetainFunc(pred func(T) bool) int {
+	s.lockBoth()
+	n := s.removeFunc(func(item T) bool { return !pred(item) })
+	s.unlockBoth()
+	s.notify()
+	return n
+}
+
+func (s *ConcurrentDeque[T]) removeFunc(pred func(T) bool) int {
+	v := s.values()
+	count := len(v)
+	var removed []int // позиции удалённых элементов
+	kept := v[:0]
+	for r, item := range v {
+		if pred(item) {
+			removed = append(removed, r)
+			continue
+		}
+		kept = append(kept, item)
+	}
+	if len(removed) == 0 {
+		return 0
+	}
+	clear(v[len(kept):]) // не удерживаем ссылки на удалённые элементы
+	if s.observer != nil {
+		for i, r := range removed {
+			side := s.nearestSide(r)
+			s.observer.OnPop(DequeEvent{Side: side, Size: count - i - 1, Capacity: s.capacity})
+		}
+	}
+	s.head = nil
+	s.tail = kept
+	s.size.Store(int64(len(kept)))
+	s.autoShrink()
+	return len(removed)
+}
+
+// endregion
+// region - internals
+
+// lockBoth/unlockBoth - захватывают (освобождают) обе блокировки; порядок захвата
+// всегда голова -> хвост
+func (s *ConcurrentDeque[T]) lockBoth() {
+	s.headMutex.Lock()
+	s.tailMutex.Lock()
+}
+func (s *ConcurrentDeque[T]) unlockBoth() {
+	s.tailMutex.Unlock()
+	s.headMutex.Unlock()
+}
+
+// reserve - резервирует место под новый элемент; false, если очередь заполнена
+func (s *ConcurrentDeque[T]) reserve() bool {
+	for {
+		n := s.size.Load()
+		if s.capacity > 0 && n >= int64(s.capacity) {
+			return false
+		}
+		if s.size.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// growNeeded/shrinkNeeded - нужно ли изменить максимальный размер очереди по политике
+// роста (в режиме DequeWithAutoResize); вызываются под одной из блокировок
+func (s *ConcurrentDeque[T]) growNeeded() bool {
+	return s.autoResize && min(s.policy.Grow(int(s.size.Load()), s.capacity), s.maxSize) > s.capacity
+}
+func (s *ConcurrentDeque[T]) shrinkNeeded() bool {
+	return s.autoResize && s.shrunkCapacity() < s.capacity
+}
+func (s *ConcurrentDeque[T]) shrunkCapacity() int {
+	size := int(s.size.Load())
+	return max(s.policy.Shrink(size, s.capacity), size, s.sizeLimit)
+}
+
+// autoGrow/autoShrink - меняют максимальный размер очереди по политике роста;
+// вызываются под обеими блокировками
+func (s *ConcurrentDeque[T]) autoGrow() {
+	if !s.autoResize {
+		return
+	}
+	if c := min(s.policy.Grow(int(s.size.Load()), s.capacity), s.maxSize); c > s.capacity {
+		oldCapacity := s.capacity
+		s.capacity, s.initialCapacity = c, c
+		s.observeResize(oldCapacity)
+	}
+}
+func (s *ConcurrentDeque[T]) autoShrink() {
+	if !s.autoResize {
+		return
+	}
+	// после удаления нескольких элементов сразу может потребоваться несколько шагов сжатия
+	oldCapacity := s.capacity
+	for c := s.shrunkCapacity(); c < s.capacity; c = s.shrunkCapacity() {
+		s.capacity, s.initialCapacity = c, c
+	}
+	s.observeResize(oldCapacity)
+}
+
+// pushLocked - добавляет элемент с вытеснением; вызывается под обеими блокировками
+func (s *ConcurrentDeque[T]) pushLocked(side Side, item T) error {
+	if s.closed.Load() {
+		return ErrClosed
+	}
+	s.autoGrow()
+	if !s.reserve() {
+		if !s.preemption {
+			s.reject(side)
+			return ErrDequeueFull
+		}
+		opposite := SideTail
+		if side == SideTail {
+			opposite = SideHead
+		}
+		evicted, _ := s.popLocked(opposite)
+		s.evict(evicted, opposite)
+		s.size.Add(1)
+	}
+	if side == SideHead {
+		s.head = append(s.head, item)
+	} else {
+		s.tail = append(s.tail, item)
+	}
+	s.observePush(side)
+	return nil
+}
+
+// popLocked - извлекает элемент, при необходимости перенося половину элементов из стека
+// противоположной стороны; вызывается под обеими блокировками
+func (s *ConcurrentDeque[T]) popLocked(side Side) (T, bool) {
+	own, other := &s.head, &s.tail
+	if side == SideTail {
+		own, other = &s.tail, &s.head
+	}
+	if len(*own) == 0 {
+		if len(*other) == 0 {
+			var empty T
+			return empty, false
+		}
+		// нижняя половина другого стека - ближайшие к нашей стороне элементы;
+		// переносим её, переиспользуя память обоих стеков
+		k := (len(*other) + 1) / 2
+		*own = append((*own)[:0], (*other)[:k]...)
+		slices.Reverse(*own)
+		n := copy(*other, (*other)[k:])
+		clear((*other)[n:]) // не удерживаем ссылки на перенесённые элементы
+		*other = (*other)[:n]
+	}
+	s.size.Add(-1)
+	return pop(own), true
+}
+
+// pop - снимает элемент с вершины стека
+func pop[T any](stack *[]T) T {
+	var empty T
+	n := len(*stack) - 1
+	item := (*stack)[n]
+	(*stack)[n] = empty // не удерживаем ссылку на извлечённый элемент
+	*stack = (*stack)[:n]
+	return item
+}
+
+func (s *ConcurrentDeque[T]) pushAll(reversed bool, side Side, items ...T) (err error) {
+	s.lockBoth()
+	if s.closed.Load() {
+		s.unlockBoth()
+		return ErrClosed
+	}
+	err = s.processAll(reversed, side, items...)
+	s.unlockBoth()
+	s.notify()
+	return err
+}
+func (s *ConcurrentDeque[T]) processAll(reversed bool, side Side, items ...T) error {
+	for i := range items {
+		item := items[i]
+		if reversed {
+			item = items[len(items)-1-i]
+		}
+		if err := s.pushLocked(side, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ConcurrentDeque[T]) popWhile(side Side, pred func(i int, item T) bool) []T {
+	s.lockBoth()
+	var v []T
+	for {
+		item, err := s.peekLocked(side)
+		if err != nil || !pred(len(v), item) {
+			break
+		}
+		item, _ = s.popLocked(side)
+		s.observePop(side)
+		s.autoShrink()
+		v = append(v, item)
+	}
+	s.unlockBoth()
+	s.notify()
+	return v
+}
+func (s *ConcurrentDeque[T]) peekLocked(side Side) (T, error) {
+	n := len(s.head) + len(s.tail)
+	if n == 0 {
+		var empty T
+		return empty, ErrDequeueEmpty
+	}
+	if side == SideHead {
+		return *s.at(0), nil
+	}
+	return *s.at(n - 1), nil
+}
+
+// values - элементы очереди от головы к хвосту; вызывается под обеими блокировками
+func (s *ConcurrentDeque[T]) values() []T {
+	n := len(s.head) + len(s.tail)
+	if n == 0 {
+		return nil
+	}
+	v := make([]T, 0, n)
+	for i := len(s.head) - 1; i >= 0; i-- {
+		v = append(v, s.head[i])
+	}
+	return append(v, s.tail...)
+}
+
+// at - указатель на i-й (считая от головы) элемент или nil; вызывается под обеими блокировками
+func (s *ConcurrentDeque[T]) at(i int) *T {
+	switch {
+	case i < 0 || i >= len(s.head)+len(s.tail):
+		return nil
+	case i < len(s.head):
+		return &s.head[len(s.head)-1-i]
+	default:
+		return &s.tail[i-len(s.head)]
+	}
+}
+func (s *ConcurrentDeque[T]) insertAt(i int, item T) error {
+	n := len(s.head) + len(s.tail)
+	if i < 0 || i > n {
+		return ErrOutOfRange
+	}
+	if s.closed.Load() {
+		return ErrClosed
+	}
+	if i == n {
+		return s.pushLocked(SideTail, item)
+	}
+	side := s.nearestSide(i)
+	s.autoGrow()
+	if !s.reserve() {
+		if !s.preemption {
+			s.reject(side)
+			return ErrDequeueFull
+		}
+		evicted, _ := s.popLocked(SideTail)
+		s.evict(evicted, SideTail)
+		s.size.Add(1)
+	}
+	if i <= len(s.head) {
+		s.head = slices.Insert(s.head, len(s.head)-i, item)
+	} else {
+		s.tail = slices.Insert(s.tail, i-len(s.head), item)
+	}
+	s.observePush(side)
+	return nil
+}
+
+// resize - меняет ограничение размера очереди, как Expand/Shrink у Deque;
+// вызывается под обеими блокировками
+func (s *ConcurrentDeque[T]) resize(capacity int) (err error) {
+	v := s.values()
+	var evicted []T
+	side := SideHead
+	if len(v) > capacity {
+		if s.preemption {
+			v, evicted = v[len(v)-capacity:], v[:len(v)-capacity]
+		} else {
+			// без вытеснения не поместившиеся элементы отбрасываются с хвоста
+			v, evicted = v[:capacity], v[capacity:]
+			slices.Reverse(evicted)
+			side = SideTail
+			err = ErrDequeueFull
+		}
+	}
+	oldCapacity := s.capacity
+	s.initialCapacity = capacity
+	s.capacity = capacity
+	s.head = nil
+	s.tail = v
+	s.size.Store(int64(len(v)))
+	s.observeResize(oldCapacity)
+	for _, item := range evicted {
+		s.evict(item, side)
+	}
+	return err
+}
+
+// restore - возвращает извлечённый элемент на прежнее место; если места в очереди
+// уже нет, элемент передаётся обработчику вытеснения
+func (s *ConcurrentDeque[T]) restore(item T, side Side) {
+	s.lockBoth()
+	if !s.reserve() {
+		s.evict(item, side)
+	} else {
+		if side == SideHead {
+			s.head = append(s.head, item)
+		} else {
+			s.tail = append(s.tail, item)
+		}
+		s.observePush(side)
+	}
+	s.unlockBoth()
+	s.notify()
+}
+func (s *ConcurrentDeque[T]) evict(item T, side Side) {
+	if s.onEvict != nil {
+		s.onEvict(item, side)
+	}
+	if s.observer != nil {
+		s.observer.OnEvict(s.event(side))
+	}
+}
+
+// endregion
+// region - observer
+
+// event - вызывается под одной из блокировок (максимальный размер меняется под обеими)
+func (s *ConcurrentDeque[T]) event(side Side) DequeEvent {
+	return DequeEvent{
+		Side:     side,
+		Size:     int(s.size.Load()),
+		Capacity: s.capacity,
+	}
+}
+func (s *ConcurrentDeque[T]) observePush(side Side) {
+	if s.observer != nil {
+		s.observer.OnPush(s.event(side))
+	}
+}
+func (s *ConcurrentDeque[T]) observePop(side Side) {
+	if s.observer != nil {
+		s.observer.OnPop(s.event(side))
+	}
+}
+func (s *ConcurrentDeque[T]) observeResize(oldCapacity int) {
+	if s.observer != nil && s.capacity != oldCapacity {
+		s.observer.OnResize(s.event(SideHead), oldCapacity)
+	}
+}
+func (s *ConcurrentDeque[T]) observeClear() {
+	if s.observer == nil {
+		return
+	}
+	for n := int(s.size.Load()) - 1; n >= 0; n-- {
+		s.observer.OnPop(DequeEvent{Side: SideHead, Size: n, Capacity: s.capacity})
+	}
+}
+func (s *ConcurrentDeque[T]) reject(side Side) {
+	if s.observer != nil {
+		s.observer.OnReject(s.event(side))
+	}
+}
+
+// nearestSide - ближайшая к i-й позиции сторона очереди; вызывается под обеими блокировками
+func (s *ConcurrentDeque[T]) nearestSide(i int) Side {
+	if 2*i < len(s.head)+len(s.tail) {
+		return SideHead
+	}
+	return SideTail
+}
+
+// endregion
+// region - waiting
+
+func (s *ConcurrentDeque[T]) popWait(ctx context.Context, pop func() (T, error)) (T, error) {
+	var item T
+	err := s.wait(ctx, func() (bool, error) {
+		var err error
+		item, err = pop()
+		switch {
+		case err == nil:
+			return true, nil
+		case s.closed.Load():
+			// закрытая очередь опустела (Close захватывает обе блокировки,
+			// поэтому элементов в ней больше не появится)
+			if s.size.Load() == 0 {
+				return false, ErrClosed
+			}
+			return false, nil
+		default:
+			return false, nil
+		}
+	})
+	return item, err
+}
+func (s *ConcurrentDeque[T]) pushWait(ctx context.Context, push func() error) error {
+	return s.wait(ctx, func() (bool, error) {
+		err := push()
+		if errors.Is(err, ErrDequeueFull) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+// wait - повторяет попытку, пока она не завершится успехом или ошибкой, ожидая между
+// попытками изменения очереди или отмены контекста
+func (s *ConcurrentDeque[T]) wait(ctx context.Context, try func() (bool, error)) error {
+	s.waiters.Add(1)
+	defer s.waiters.Add(-1)
+	for {
+		// канал получаем до попытки, чтобы не пропустить изменение между ними
+		changed := s.changed()
+		if ok, err := try(); ok || err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+func (s *ConcurrentDeque[T]) changed() <-chan struct{} {
+	s.signalMutex.Lock()
+	defer s.signalMutex.Unlock()
+	if s.signal == nil {
+		s.signal = make(chan struct{})
+	}
+	return s.signal
+}
+
+// notify - будит ожидающих (если они есть) и, если закрытая очередь опустела,
+// закрывает канал Done(); вызывается без блокировок очереди
+func (s *ConcurrentDeque[T]) notify() {
+	if s.waiters.Load() > 0 {
+		s.signalMutex.Lock()
+		if s.signal != nil {
+			close(s.signal)
+			s.signal = nil
+		}
+		s.signalMutex.Unlock()
+	}
+	if s.closed.Load() && s.size.Load() == 0 {
+		s.doneOnce.Do(func() { close(s.done) })
+	}
+}
+
+// endregion
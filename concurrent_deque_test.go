@@ -0,0 +1,253 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrentDequeBasic(t *testing.T) {
+
+	d := NewConcurrentDeque[int]()
+	assert.Equal(t, 0, d.Size())
+	assert.Equal(t, -1, d.Capacity())
+
+	assert.NoError(t, d.PushHead(2))
+	assert.NoError(t, d.PushHead(1))
+	assert.NoError(t, d.PushTail(3))
+	assert.NoError(t, d.PushTail(4))
+	assert.Equal(t, []int{1, 2, 3, 4}, d.Values())
+	assert.Equal(t, []int{1, 2, 3, 4}, slices.Collect(d.All()))
+	assert.Equal(t, []int{4, 3, 2, 1}, slices.Collect(d.Backward()))
+
+	// извлечение с одной стороны переносит элементы из стека другой
+	for _, expected := range []int{1, 2, 3, 4} {
+		v, err := d.PopHead()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+	_, err := d.PopHead()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+	_, err = d.PeekTail()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+
+	assert.NoError(t, d.PushHeadAll(1, 2, 3))
+	for _, expected := range []int{1, 2, 3} {
+		v, err := d.PeekTail()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+		v, err = d.PopTail()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+	_, err = d.PopTail()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+
+}
+
+// TestConcurrentDequeMatchesDeque - сравнивает результаты случайных последовательностей
+// операций над ConcurrentDeque и Deque
+func TestConcurrentDequeMatchesDeque(t *testing.T) {
+
+	configs := map[string][]DequeOption{
+		"unbounded":            nil,
+		"bounded":              {DequeWithSizeLimit(10)},
+		"bounded, preemptive":  {DequeWithSizeLimit(10), DequeWithPreemption()},
+		"bounded, single item": {DequeWithSizeLimit(1), DequeWithPreemption()},
+		"auto-resize":          {DequeWithSizeLimit(4), DequeWithAutoResize(16)},
+		"auto-resize, fixed":   {DequeWithSizeLimit(4), DequeWithAutoResize(12), DequeWithPreemption(), DequeWithGrowthPolicy(FixedGrowth(2, 12))},
+	}
+
+	for name, opts := range configs {
+		t.Run(name, func(t *testing.T) {
+
+			type eviction struct {
+				item int
+				side Side
+			}
+			var expectedEvicted, actualEvicted []eviction
+
+			expectedStats, actualStats := NewDequeCounter(), NewDequeCounter()
+			stats := func(c *DequeCounter) DequeStats {
+				s := c.Stats()
+				s.Started = time.Time{}
+				return s
+			}
+
+			expected := NewDeque[int](append(slices.Clone(opts), DequeWithObserver(expectedStats), DequeWithEvictionHandler(func(item int, side Side) {
+				expectedEvicted = append(expectedEvicted, eviction{item, side})
+			}))...)
+			actual := NewConcurrentDeque[int](append(slices.Clone(opts), DequeWithObserver(actualStats), DequeWithEvictionHandler(func(item int, side Side) {
+				actualEvicted = append(actualEvicted, eviction{item, side})
+			}))...)
+
+			rnd := rand.New(rand.NewSource(1))
+			for i := 0; i < 5000; i++ {
+				op := rnd.Intn(21)
+				n := rnd.Intn(12) - 1
+				var e, a any
+				switch op {
+				case 0, 1:
+					e, a = expected.PushHead(i), actual.PushHead(i)
+				case 2, 3:
+					e, a = expected.PushTail(i), actual.PushTail(i)
+				case 4:
+					e, a = fmt.Sprint(expected.PopHead()), fmt.Sprint(actual.PopHead())
+				case 5:
+					e, a = fmt.Sprint(expected.PopTail()), fmt.Sprint(actual.PopTail())
+				case 6:
+					e, a = fmt.Sprint(expected.PeekHead()), fmt.Sprint(actual.PeekHead())
+				case 7:
+					e, a = fmt.Sprint(expected.PeekTail()), fmt.Sprint(actual.PeekTail())
+				case 8:
+					e, a = expected.InsertAt(n, i), actual.InsertAt(n, i)
+				case 9:
+					e, a = fmt.Sprint(expected.RemoveAt(n)), fmt.Sprint(actual.RemoveAt(n))
+				case 10:
+					e, a = expected.Set(n, i), actual.Set(n, i)
+				case 11:
+					e, a = expected.Swap(n, n/2), actual.Swap(n, n/2)
+				case 12:
+					e, a = expected.PopHeadN(n/3), actual.PopHeadN(n/3)
+				case 13:
+					pred := func(v int) bool { return v%3 != 0 }
+					e, a = expected.PopTailWhile(pred), actual.PopTailWhile(pred)
+				case 14:
+					items := []int{i, i + 1, i + 2}
+					e, a = expected.PushTailAllReversed(items...), actual.PushTailAllReversed(items...)
+				case 16:
+					expected.Rotate(n - 5)
+					actual.Rotate(n - 5)
+				case 17:
+					expected.Reverse()
+					actual.Reverse()
+				case 18:
+					pred := func(v int) bool { return v%7 == n }
+					e, a = expected.RemoveFunc(pred), actual.RemoveFunc(pred)
+				case 19:
+					pred := func(v int) bool { return v%5 == n }
+					e, a = expected.IndexFunc(pred), actual.IndexFunc(pred)
+				case 20:
+					side := Side(n & 1)
+					e, a = expected.Push(side, i, i+1), actual.Push(side, i, i+1)
+				case 15:
+					if n%2 == 0 {
+						e, a = expected.Expand(), actual.Expand()
+					} else {
+						e, a = expected.Shrink(), actual.Shrink()
+					}
+				}
+				if !assert.Equal(t, e, a, "op %d, step %d", op, i) ||
+					!assert.Equal(t, expected.Values(), actual.Values(), "op %d, step %d", op, i) ||
+					!assert.Equal(t, expected.Size(), actual.Size(), "op %d, step %d", op, i) ||
+					!assert.Equal(t, expected.Capacity(), actual.Capacity(), "op %d, step %d", op, i) ||
+					!assert.Equal(t, stats(expectedStats), stats(actualStats), "op %d, step %d", op, i) {
+					return
+				}
+			}
+			assert.Equal(t, expectedEvicted, actualEvicted)
+			assert.Equal(t, expected.DrainAll(), actual.DrainAll())
+			assert.Equal(t, expected.Capacity(), actual.Capacity())
+			assert.Equal(t, stats(expectedStats), stats(actualStats))
+
+		})
+	}
+
+}
+func TestConcurrentDequeWaitAndClose(t *testing.T) {
+
+	d := NewConcurrentDeque[int](
+		DequeWithSizeLimit(1),
+	)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, d.PushTail(1))
+	}()
+	v, err := d.PopHeadWait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	assert.NoError(t, d.PushHeadWait(context.Background(), 2))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.PushTailWait(ctx, 3), context.DeadlineExceeded)
+
+	d.Close()
+	assert.True(t, d.Closed())
+	assert.ErrorIs(t, d.PushTail(3), ErrClosed)
+
+	v, err = d.PopTailWait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	select {
+	case <-d.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "drained deque is not done")
+	}
+	_, err = d.PopHeadWait(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+
+}
+func TestBoundedConcurrentDequeNeverOverflows(t *testing.T) {
+	testBoundedNeverOverflows(t, func(opts ...DequeOption) concurrentIntDeque {
+		return NewConcurrentDeque[int](opts...)
+	})
+}
+func TestConcurrentDequeLinearizability(t *testing.T) {
+	testLinearizability(t, func(opts ...DequeOption) concurrentIntDeque {
+		return NewConcurrentDeque[int](opts...)
+	})
+}
+
+// BenchmarkDequeContention - сравнивает Deque и ConcurrentDeque при разном GOMAXPROCS:
+// в "mixed" каждая горутина добавляет в хвост и извлекает из головы, в "fan-in"
+// половина горутин только добавляет в хвост, половина - только извлекает из головы
+func BenchmarkDequeContention(b *testing.B) {
+	implementations := []struct {
+		name     string
+		newDeque func() concurrentIntDeque
+	}{
+		{"Deque", func() concurrentIntDeque { return NewDeque[int]() }},
+		{"ConcurrentDeque", func() concurrentIntDeque { return NewConcurrentDeque[int]() }},
+	}
+	for _, procs := range []int{1, 2, 4, 8} {
+		for _, impl := range implementations {
+			b.Run(fmt.Sprintf("mixed/%s/procs=%d", impl.name, procs), func(b *testing.B) {
+				defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+				d := impl.newDeque()
+				b.RunParallel(func(pb *testing.PB) {
+					for i := 0; pb.Next(); i++ {
+						_ = d.PushTail(i)
+						_, _ = d.PopHead()
+					}
+				})
+			})
+			b.Run(fmt.Sprintf("fan-in/%s/procs=%d", impl.name, procs), func(b *testing.B) {
+				defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+				d := impl.newDeque()
+				for i := 0; i < 1000; i++ {
+					_ = d.PushTail(i)
+				}
+				var workers atomic.Int64
+				b.RunParallel(func(pb *testing.PB) {
+					producer := workers.Add(1)%2 == 0
+					for i := 0; pb.Next(); i++ {
+						if producer {
+							_ = d.PushTail(i)
+						} else {
+							_, _ = d.PopHead()
+						}
+					}
+				})
+			})
+		}
+	}
+}
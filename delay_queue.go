@@ -0,0 +1,262 @@
+package container
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// region - errors
+
+var (
+	ErrNotReady = errors.New("no item is ready yet")
+)
+
+// endregion
+
+// region - options
+
+type delayQueueConfig struct {
+	clock     Clock
+	sizeLimit int
+}
+
+type DelayQueueOption func(*delayQueueConfig)
+
+// DelayQueueWithClock - источник времени очереди (по умолчанию SystemClock)
+func DelayQueueWithClock(clock Clock) DelayQueueOption {
+	return func(config *delayQueueConfig) {
+		config.clock = clock
+	}
+}
+
+// DelayQueueWithSizeLimit - максимальное количество элементов в очереди; добавление
+// в заполненную очередь отклоняется с ошибкой ErrDequeueFull
+func DelayQueueWithSizeLimit(limit int) DelayQueueOption {
+	return func(config *delayQueueConfig) {
+		config.sizeLimit = limit
+	}
+}
+
+// endregion
+
+// DelayHandle - идентификатор элемента DelayQueue для отмены (Cancel)
+type DelayHandle uint64
+
+type delayEntry[T any] struct {
+	item   T
+	at     time.Time   // Время, с которого элемент можно извлечь
+	handle DelayHandle // Элементы с одинаковым временем извлекаются в порядке добавления
+	index  int         // Позиция элемента в куче
+}
+
+// delayHeap - куча элементов, упорядоченных по времени готовности
+type delayHeap[T any] []*delayEntry[T]
+
+func (h delayHeap[T]) Len() int {
+	return len(h)
+}
+func (h delayHeap[T]) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].handle < h[j].handle
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h delayHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *delayHeap[T]) Push(x any) {
+	e := x.(*delayEntry[T])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *delayHeap[T]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+// DelayQueue - очередь с отложенной выдачей: каждый элемент добавляется со временем,
+// начиная с которого его можно извлечь, и элементы извлекаются в порядке этого
+// времени. Добавленный элемент можно отменить по DelayHandle.
+type DelayQueue[T any] struct {
+	items    delayHeap[T]                   // Элементы в порядке времени готовности
+	handles  map[DelayHandle]*delayEntry[T] // Элементы по идентификатору
+	clock    Clock                          // Источник времени
+	capacity int                            // Максимальный размер очереди (-1 для безлимитной очереди)
+	last     DelayHandle                    // Последний выданный идентификатор
+	signal   chan struct{}                  // Закрывается при изменении очереди (см. changed)
+	mutex    sync.Mutex
+}
+
+func NewDelayQueue[T any](opts ...DelayQueueOption) *DelayQueue[T] {
+
+	config := &delayQueueConfig{
+		clock:     SystemClock(),
+		sizeLimit: -1,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.sizeLimit <= 0 {
+		config.sizeLimit = -1
+	}
+
+	return &DelayQueue[T]{
+		handles:  make(map[DelayHandle]*delayEntry[T]),
+		clock:    config.clock,
+		capacity: config.sizeLimit,
+		mutex:    sync.Mutex{},
+	}
+
+}
+
+// Push - добавляет элемент, который можно будет извлечь начиная с момента at
+func (s *DelayQueue[T]) Push(item T, at time.Time) (DelayHandle, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.capacity > 0 && len(s.items) >= s.capacity {
+		return 0, ErrDequeueFull
+	}
+	s.last++
+	e := &delayEntry[T]{
+		item:   item,
+		at:     at,
+		handle: s.last,
+	}
+	heap.Push(&s.items, e)
+	s.handles[e.handle] = e
+	s.notify()
+	return e.handle, nil
+}
+
+// PushAfter - добавляет элемент, который можно будет извлечь через d
+func (s *DelayQueue[T]) PushAfter(item T, d time.Duration) (DelayHandle, error) {
+	return s.Push(item, s.clock.Now().Add(d))
+}
+
+// Cancel - удаляет элемент из очереди; false, если элемент уже извлечён или отменён
+func (s *DelayQueue[T]) Cancel(h DelayHandle) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	e, ok := s.handles[h]
+	if !ok {
+		return false
+	}
+	heap.Remove(&s.items, e.index)
+	delete(s.handles, h)
+	s.notify()
+	return true
+}
+
+// Pop - извлекает готовый элемент с наименьшим временем готовности; если очередь
+// пуста, возвращает ErrDequeueEmpty, а если ни один элемент ещё не готов - ErrNotReady
+func (s *DelayQueue[T]) Pop() (T, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.items) == 0 {
+		var empty T
+		return empty, ErrDequeueEmpty
+	}
+	if s.items[0].at.After(s.clock.Now()) {
+		var empty T
+		return empty, ErrNotReady
+	}
+	return s.pop(), nil
+}
+
+// PopWait - извлекает элемент, дожидаясь его готовности (или появления в пустой
+// очереди); ожидание прерывается отменой ctx
+func (s *DelayQueue[T]) PopWait(ctx context.Context) (T, error) {
+	for {
+		s.mutex.Lock()
+		var timer Timer
+		var fired <-chan time.Time
+		if len(s.items) > 0 {
+			wait := s.items[0].at.Sub(s.clock.Now())
+			if wait <= 0 {
+				item := s.pop()
+				s.mutex.Unlock()
+				return item, nil
+			}
+			timer = s.clock.NewTimer(wait)
+			fired = timer.C()
+		}
+		changed := s.changed()
+		s.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-fired:
+		}
+		// при следующей итерации таймер запускается заново (возможно, на другое время)
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			var empty T
+			return empty, err
+		}
+	}
+}
+
+// Peek - возвращает элемент с наименьшим временем готовности (независимо от того,
+// наступило ли оно) и это время, не извлекая элемент
+func (s *DelayQueue[T]) Peek() (T, time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.items) == 0 {
+		var empty T
+		return empty, time.Time{}, ErrDequeueEmpty
+	}
+	return s.items[0].item, s.items[0].at, nil
+}
+
+func (s *DelayQueue[T]) Flush() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.items = nil
+	s.handles = make(map[DelayHandle]*delayEntry[T])
+	s.notify()
+}
+
+// Size - возвращает количество элементов в очереди (готовых и ожидающих)
+func (s *DelayQueue[T]) Size() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.items)
+}
+
+// Capacity - возвращает максимальный размер очереди; -1 для неограниченной очереди
+func (s *DelayQueue[T]) Capacity() int {
+	return s.capacity
+}
+
+func (s *DelayQueue[T]) pop() T {
+	e := heap.Pop(&s.items).(*delayEntry[T])
+	delete(s.handles, e.handle)
+	s.notify()
+	return e.item
+}
+
+// changed - возвращает канал, который будет закрыт при следующем изменении очереди;
+// вызывается под блокировкой
+func (s *DelayQueue[T]) changed() <-chan struct{} {
+	if s.signal == nil {
+		s.signal = make(chan struct{})
+	}
+	return s.signal
+}
+func (s *DelayQueue[T]) notify() {
+	if s.signal != nil {
+		close(s.signal)
+		s.signal = nil
+	}
+}
@@ -0,0 +1,102 @@
+package container
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDelayQueuePop(t *testing.T) {
+
+	clock := NewManualClock(time.Unix(1000, 0))
+	q := NewDelayQueue[string](DelayQueueWithClock(clock), DelayQueueWithSizeLimit(4))
+
+	_, err := q.Pop()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+
+	_, err = q.PushAfter("c", 3*time.Second)
+	assert.NoError(t, err)
+	_, err = q.PushAfter("a", time.Second)
+	assert.NoError(t, err)
+	hb, err := q.PushAfter("b", 2*time.Second)
+	assert.NoError(t, err)
+	_, err = q.PushAfter("a2", time.Second) // то же время - после "a"
+	assert.NoError(t, err)
+	_, err = q.PushAfter("x", time.Second)
+	assert.ErrorIs(t, err, ErrDequeueFull)
+
+	_, err = q.Pop()
+	assert.ErrorIs(t, err, ErrNotReady)
+	v, at, err := q.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", v)
+	assert.Equal(t, time.Unix(1001, 0), at)
+
+	assert.True(t, q.Cancel(hb))
+	assert.False(t, q.Cancel(hb))
+	assert.Equal(t, 3, q.Size())
+
+	clock.Advance(5 * time.Second)
+	var got []string
+	for {
+		v, err := q.Pop()
+		if err != nil {
+			assert.ErrorIs(t, err, ErrDequeueEmpty)
+			break
+		}
+		got = append(got, v)
+	}
+	assert.Equal(t, []string{"a", "a2", "c"}, got)
+
+}
+
+func TestDelayQueuePopWait(t *testing.T) {
+
+	clock := NewManualClock(time.Unix(1000, 0))
+	q := NewDelayQueue[int](DelayQueueWithClock(clock))
+
+	_, err := q.PushAfter(2, 10*time.Second)
+	assert.NoError(t, err)
+
+	result := make(chan int)
+	go func() {
+		v, err := q.PopWait(context.Background())
+		assert.NoError(t, err)
+		result <- v
+	}()
+
+	// более ранний элемент будит ожидающего, и он ждёт уже до его готовности
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	_, err = q.PushAfter(1, time.Second)
+	assert.NoError(t, err)
+	select {
+	case <-result:
+		t.Fatal("item released before it is due")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// прежний таймер остановлен при пробуждении, а не ждёт своего времени
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(time.Second)
+	assert.Equal(t, 1, <-result)
+	assert.Equal(t, 1, q.Size())
+	assert.Equal(t, 0, clock.Timers())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.PopWait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+}
+
+func TestDelayQueueSystemClock(t *testing.T) {
+	q := NewDelayQueue[int]()
+	start := time.Now()
+	_, err := q.PushAfter(1, 20*time.Millisecond)
+	assert.NoError(t, err)
+	v, err := q.PopWait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
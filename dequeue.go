@@ -1,15 +1,24 @@
 package container
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"iter"
+	"slices"
 	"sync"
 )
 
 // region - options
 
 type queueConfig struct {
-	sizeLimit  int
-	preemption bool // Вытесняет элемент с противоположной стороны очереди при вставке нового элемента в заполненную очередь
+	sizeLimit       int
+	preemption      bool // Вытесняет элемент с противоположной стороны очереди при вставке нового элемента в заполненную очередь
+	evictionHandler any  // func(item T, side Side); тип проверяется в конструкторе очереди
+	growthPolicy    GrowthPolicy
+	autoResize      bool // Менять ограничение размера по политике роста при добавлении и извлечении элементов
+	maxSize         int  // Предел ограничения размера в режиме autoResize
+	observer        Observer
 }
 type DequeOption func(*queueConfig)
 
@@ -27,12 +36,123 @@ func DequeWithPreemption() DequeOption {
 	}
 }
 
+// DequeWithEvictionHandler - обработчик элементов, вытесненных из очереди (при вставке
+// в заполненную очередь с вытеснением или при усечении очереди в Shrink); получает
+// вытесненный элемент и сторону очереди, с которой он был удалён. Обработчик
+// вызывается под блокировкой очереди и не должен обращаться к ней самой.
+func DequeWithEvictionHandler[T any](handler func(item T, side Side)) DequeOption {
+	return func(config *queueConfig) {
+		config.evictionHandler = handler
+	}
+}
+
+// DequeWithGrowthPolicy - политика изменения ограничения размера очереди в Expand/Shrink
+// и в режиме DequeWithAutoResize (по умолчанию - DefaultGrowthPolicy, а в режиме
+// DequeWithAutoResize - AutoResizeGrowthPolicy)
+func DequeWithGrowthPolicy(policy GrowthPolicy) DequeOption {
+	return func(config *queueConfig) {
+		config.growthPolicy = policy
+	}
+}
+
+// DequeWithAutoResize - менять ограничение размера ограниченной очереди по политике
+// роста при каждом добавлении и извлечении элемента; ограничение не становится
+// меньше заданного DequeWithSizeLimit и количества элементов в очереди и не растёт
+// выше maxSize (maxSize меньше DequeWithSizeLimit означает "не расширять"). Пока
+// предел не достигнут, расширение заполненной очереди предпочтительнее вытеснения;
+// после этого очередь ведёт себя как обычная ограниченная (отказ или вытеснение).
+// Expand/Shrink по-прежнему можно вызывать явно. Порог сжатия собственной политики
+// должен быть заметно ниже порога расширения (см. AutoResizeGrowthPolicy).
+func DequeWithAutoResize(maxSize int) DequeOption {
+	return func(config *queueConfig) {
+		config.autoResize = true
+		config.maxSize = maxSize
+	}
+}
+
+// DequeWithObserver - получатель событий очереди (добавление, извлечение, отказ,
+// вытеснение, изменение размера), например DequeCounter
+func DequeWithObserver(observer Observer) DequeOption {
+	return func(config *queueConfig) {
+		config.observer = observer
+	}
+}
+
+func newQueueConfig(opts ...DequeOption) *queueConfig {
+	qc := &queueConfig{
+		sizeLimit:  -1,
+		preemption: false,
+	}
+	for _, opt := range opts {
+		opt(qc)
+	}
+	// для безлимитных очередей вытеснение и изменение размера не нужны
+	if qc.sizeLimit <= 0 {
+		qc.preemption = false
+		qc.autoResize = false
+	}
+	qc.maxSize = max(qc.maxSize, qc.sizeLimit)
+	if qc.growthPolicy == nil && qc.autoResize {
+		qc.growthPolicy = AutoResizeGrowthPolicy()
+	}
+	if qc.growthPolicy == nil {
+		qc.growthPolicy = DefaultGrowthPolicy()
+	}
+	return qc
+}
+
+// evictionHandler - возвращает заданный опцией DequeWithEvictionHandler обработчик,
+// проверяя, что он соответствует типу элементов очереди
+func evictionHandler[T any](qc *queueConfig) func(T, Side) {
+	if qc.evictionHandler == nil {
+		return nil
+	}
+	h, ok := qc.evictionHandler.(func(T, Side))
+	if !ok {
+		panic(fmt.Sprintf("container: eviction handler %T does not match element type", qc.evictionHandler))
+	}
+	return h
+}
+
+// endregion
+// region - side
+
+// Side - сторона (конец) очереди
+type Side int
+
+const (
+	SideHead Side = iota // Голова очереди
+	SideTail             // Хвост очереди
+)
+
+func (s Side) String() string {
+	switch s {
+	case SideHead:
+		return "head"
+	case SideTail:
+		return "tail"
+	default:
+		return fmt.Sprintf("Side(%d)", int(s))
+	}
+}
+
+// Opposite - противоположная сторона очереди: при добавлении в заполненную очередь
+// с вытеснением элемент вытесняется именно с неё
+func (s Side) Opposite() Side {
+	if s == SideHead {
+		return SideTail
+	}
+	return SideHead
+}
+
 // endregion
 // region - errors
 
 var (
 	ErrDequeueEmpty = errors.New("empty dequeue")
 	ErrDequeueFull  = errors.New("dequeue full")
+	ErrOutOfRange   = errors.New("index out of range")
+	ErrClosed       = errors.New("container closed")
 )
 
 // endregion
@@ -45,48 +165,65 @@ const (
 )
 
 type Deque[T any] struct {
-	items           []T  // Элементы очереди
-	initialCapacity int  // Начальая ёмкость
-	capacity        int  // Максимальный размер очереди (-1 для безлимитной очереди)
-	preemption      bool // Вытеснять крайний элемент с противоположной стороны при добавлении нового в заполненную очередь
+	items           []T           // Кольцевой буфер элементов очереди
+	head            int           // Индекс головного элемента в буфере
+	count           int           // Количество элементов в очереди
+	initialCapacity int           // Начальая ёмкость
+	capacity        int           // Максимальный размер очереди (-1 для безлимитной очереди)
+	sizeLimit       int           // Заданный опцией максимальный размер (нижняя граница при автоматическом сжатии)
+	preemption      bool          // Вытеснять крайний элемент с противоположной стороны при добавлении нового в заполненную очередь
+	onEvict         func(T, Side) // Обработчик вытесненных элементов
+	policy          GrowthPolicy  // Политика изменения максимального размера
+	autoResize      bool          // Менять максимальный размер при добавлении и извлечении элементов
+	maxSize         int           // Предел максимального размера при автоматическом расширении
+	observer        Observer      // Получатель событий очереди
+	signal          chan struct{} // Закрывается при изменении содержимого очереди (для блокирующих операций)
+	closed          bool          // Очередь закрыта для добавления элементов
+	done            chan struct{} // Закрывается, когда очередь закрыта и пуста
 	mutex           sync.RWMutex
 }
 
 func NewDeque[T any](opts ...DequeOption) *Deque[T] {
 
-	qc := &queueConfig{
-		sizeLimit:  -1,
-		preemption: false,
-	}
-	for _, opt := range opts {
-		opt(qc)
-	}
-
-	// для безлимитных очередей вытеснение не нужно
-	if qc.sizeLimit <= 0 {
-		qc.preemption = false
-	}
+	qc := newQueueConfig(opts...)
 
 	// задаём (начальный или постоянный) размер очереди
 	initialCapacity := 8
 	if qc.sizeLimit > 0 {
-		// TODO: м.б. надо это пересмотреть - сделать макс. размер в несколько раз больше
-		//       заданного настройкой, чтобы снизить количество "реаллокаций" массива
-		//       (можно это задавать отдельным конфигурационным параметром, чтобы можно было
-		//        регулировать размер потребляемой памяти vs. число реаллокаций)
+		// дальнейшее изменение размера - по политике роста (DequeWithGrowthPolicy,
+		// DequeWithAutoResize)
 		initialCapacity = qc.sizeLimit
 	}
 
 	return &Deque[T]{
-		items:           make([]T, 0, initialCapacity),
+		items:           make([]T, initialCapacity),
 		initialCapacity: initialCapacity,
 		capacity:        qc.sizeLimit,
+		sizeLimit:       qc.sizeLimit,
 		preemption:      qc.preemption,
+		onEvict:         evictionHandler[T](qc),
+		policy:          qc.growthPolicy,
+		autoResize:      qc.autoResize,
+		maxSize:         qc.maxSize,
+		observer:        qc.observer,
 		mutex:           sync.RWMutex{},
 	}
 
 }
 
+// DequeFrom - создаёт очередь и заполняет её (с хвоста) элементами из последовательности;
+// в заполненную очередь без вытеснения лишние элементы не добавляются
+func DequeFrom[T any](seq iter.Seq[T], opts ...DequeOption) *Deque[T] {
+	d := NewDeque[T](opts...)
+	for item := range seq {
+		if d.checkSize() != nil {
+			break
+		}
+		d.pushTailInternal(item)
+	}
+	return d
+}
+
 //func (s *Deque[T]) Lock() {
 //	s.mutex.Lock()
 //}
@@ -94,53 +231,100 @@ func NewDeque[T any](opts ...DequeOption) *Deque[T] {
 //	s.mutex.Unlock()
 //}
 
+// Close - закрывает очередь: дальнейшие попытки добавления элементов завершаются
+// ошибкой ErrClosed, ожидающие добавления или извлечения (из пустой очереди)
+// получают ErrClosed. Оставшиеся элементы можно извлечь обычным образом.
+// Повторный вызов ничего не делает.
+func (s *Deque[T]) Close() {
+	s.mutex.Lock()
+	if !s.closed {
+		s.closed = true
+		s.notify()
+	}
+	s.mutex.Unlock()
+}
+
+// Closed - возвращает true, если очередь закрыта
+func (s *Deque[T]) Closed() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.closed
+}
+
+// Done - возвращает канал, который закрывается, когда очередь закрыта и все
+// элементы из неё извлечены
+func (s *Deque[T]) Done() <-chan struct{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.doneChan()
+}
+
 func (s *Deque[T]) Flush() {
 	s.mutex.Lock()
 	s.flush()
 	s.mutex.Unlock()
 }
 func (s *Deque[T]) flush() {
-	s.items = make([]T, 0, s.initialCapacity)
-	s.capacity = s.initialCapacity
+	s.observeClear()
+	if s.autoResize {
+		// автоматически расширенная очередь возвращается к заданному ограничению размера
+		s.initialCapacity = s.sizeLimit
+	}
+	s.items = make([]T, s.initialCapacity)
+	s.head = 0
+	s.count = 0
+	if s.capacity > 0 {
+		oldCapacity := s.capacity
+		s.capacity = s.initialCapacity
+		s.observeResize(oldCapacity)
+	}
+	s.notify()
 }
 
 func (s *Deque[T]) Replace(items ...T) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
 	s.flush()
-	return s.processAll(true, s.pushHeadInternal, items...)
+	return s.processAll(true, SideHead, items...)
 }
 
 func (s *Deque[T]) PushHeadAll(items ...T) (err error) {
 	s.mutex.Lock()
-	err = s.processAll(false, s.pushHeadInternal, items...)
+	err = s.pushAll(false, SideHead, items...)
 	s.mutex.Unlock()
 	return err
 }
 func (s *Deque[T]) PushHeadAllReversed(items ...T) (err error) {
 	s.mutex.Lock()
-	err = s.processAll(true, s.pushHeadInternal, items...)
+	err = s.pushAll(true, SideHead, items...)
 	s.mutex.Unlock()
 	return err
 }
 func (s *Deque[T]) PushHead(item T) error {
 
-	// TODO: возможно, лучше это всё обернуть в один WriteLock
+	// проверка и добавление - под одной блокировкой, иначе конкурирующие
+	// вызовы могут одновременно пройти проверку и переполнить очередь
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	s.mutex.RLock()
-	err := s.checkSize()
-	s.mutex.RUnlock()
-	if err != nil {
-		return err
+	if err := s.checkPush(); err != nil {
+		return s.reject(err, SideHead)
 	}
-
-	s.mutex.Lock()
 	s.pushHeadInternal(item)
-	s.mutex.Unlock()
 
 	return nil
 
 }
+
+// PushHeadWait - добавляет элемент в голову очереди; если ограниченная очередь
+// без вытеснения заполнена, ожидает освобождения места, отмены контекста (возвращает
+// ctx.Err()) или закрытия очереди (возвращает ErrClosed)
+func (s *Deque[T]) PushHeadWait(ctx context.Context, item T) error {
+	return s.pushWait(ctx, item, s.pushHeadInternal)
+}
 func (s *Deque[T]) PopHead() (T, error) {
 
 	if s == nil {
@@ -148,25 +332,23 @@ func (s *Deque[T]) PopHead() (T, error) {
 		return empty, ErrDequeueEmpty
 	}
 
-	s.mutex.RLock()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	if len(s.items) == 0 {
-		s.mutex.RUnlock()
+	if s.count == 0 {
 		var empty T
 		return empty, ErrDequeueEmpty
 	}
 
-	s.mutex.RUnlock()
-
-	s.mutex.Lock()
-
-	item := s.items[0]
-	s.items = s.items[1:]
+	return s.popHeadInternal(), nil
 
-	s.mutex.Unlock()
-
-	return item, nil
+}
 
+// PopHeadWait - извлекает элемент из головы очереди; если очередь пуста, ожидает
+// появления элемента, отмены контекста (возвращает ctx.Err()) или закрытия очереди
+// (возвращает ErrClosed)
+func (s *Deque[T]) PopHeadWait(ctx context.Context) (T, error) {
+	return s.popWait(ctx, s.popHeadInternal)
 }
 func (s *Deque[T]) PeekHead() (T, error) {
 
@@ -177,13 +359,13 @@ func (s *Deque[T]) PeekHead() (T, error) {
 
 	s.mutex.RLock()
 
-	if len(s.items) == 0 {
+	if s.count == 0 {
 		s.mutex.RUnlock()
 		var empty T
 		return empty, ErrDequeueEmpty
 	}
 
-	item := s.items[0]
+	item := s.items[s.head]
 
 	s.mutex.RUnlock()
 
@@ -193,33 +375,37 @@ func (s *Deque[T]) PeekHead() (T, error) {
 
 func (s *Deque[T]) PushTailAll(items ...T) (err error) {
 	s.mutex.Lock()
-	err = s.processAll(false, s.pushTailInternal, items...)
+	err = s.pushAll(false, SideTail, items...)
 	s.mutex.Unlock()
 	return err
 }
 func (s *Deque[T]) PushTailAllReversed(items ...T) (err error) {
 	s.mutex.Lock()
-	err = s.processAll(true, s.pushTailInternal, items...)
+	err = s.pushAll(true, SideTail, items...)
 	s.mutex.Unlock()
 	return err
 }
 func (s *Deque[T]) PushTail(item T) error {
 
-	// TODO: возможно, лучше это всё обернуть в один WriteLock
+	// проверка и добавление - под одной блокировкой, иначе конкурирующие
+	// вызовы могут одновременно пройти проверку и переполнить очередь
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	s.mutex.RLock()
-	err := s.checkSize()
-	s.mutex.RUnlock()
-	if err != nil {
-		return err
+	if err := s.checkPush(); err != nil {
+		return s.reject(err, SideTail)
 	}
-
-	s.mutex.Lock()
 	s.pushTailInternal(item)
-	s.mutex.Unlock()
 
 	return nil
 }
+
+// PushTailWait - добавляет элемент в хвост очереди; если ограниченная очередь
+// без вытеснения заполнена, ожидает освобождения места, отмены контекста (возвращает
+// ctx.Err()) или закрытия очереди (возвращает ErrClosed)
+func (s *Deque[T]) PushTailWait(ctx context.Context, item T) error {
+	return s.pushWait(ctx, item, s.pushTailInternal)
+}
 func (s *Deque[T]) PopTail() (T, error) {
 
 	if s == nil {
@@ -227,25 +413,23 @@ func (s *Deque[T]) PopTail() (T, error) {
 		return empty, ErrDequeueEmpty
 	}
 
-	s.mutex.RLock()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	if len(s.items) == 0 {
-		s.mutex.RUnlock()
+	if s.count == 0 {
 		var empty T
 		return empty, ErrDequeueEmpty
 	}
 
-	s.mutex.RUnlock()
-
-	s.mutex.Lock()
-
-	item := s.items[len(s.items)-1]
-	s.items = s.items[:len(s.items)-1]
+	return s.popTailInternal(), nil
 
-	s.mutex.Unlock()
-
-	return item, nil
+}
 
+// PopTailWait - извлекает элемент из хвоста очереди; если очередь пуста, ожидает
+// появления элемента, отмены контекста (возвращает ctx.Err()) или закрытия очереди
+// (возвращает ErrClosed)
+func (s *Deque[T]) PopTailWait(ctx context.Context) (T, error) {
+	return s.popWait(ctx, s.popTailInternal)
 }
 func (s *Deque[T]) PeekTail() (T, error) {
 
@@ -256,13 +440,13 @@ func (s *Deque[T]) PeekTail() (T, error) {
 
 	s.mutex.RLock()
 
-	if len(s.items) == 0 {
+	if s.count == 0 {
 		s.mutex.RUnlock()
 		var empty T
 		return empty, ErrDequeueEmpty
 	}
 
-	item := s.items[len(s.items)-1]
+	item := s.items[s.index(s.count-1)]
 
 	s.mutex.RUnlock()
 
@@ -271,17 +455,15 @@ func (s *Deque[T]) PeekTail() (T, error) {
 
 func (s *Deque[T]) Expand() (err error) {
 
+	s.mutex.Lock()
+
 	if s.capacity <= 0 {
+		s.mutex.Unlock()
 		return // для unbounded deque ничего не делаем
 	}
 
-	s.mutex.Lock()
-
-	if float64(s.size()) >= float64(s.capacity)*expandFactor {
-		arr := s.values()
-		s.initialCapacity = int(float64(s.capacity) * expandCoefficient)
-		s.flush()
-		err = s.processAll(false, s.pushTailInternal, arr...)
+	if c := s.policy.Grow(s.size(), s.capacity); c > s.capacity {
+		err = s.setCapacity(c)
 	}
 
 	s.mutex.Unlock()
@@ -293,17 +475,15 @@ func (s *Deque[T]) Shrink() (err error) {
 
 	// TODO: сжимать без учёта текущего размера, отрезая хвост (конфигурабельно)
 
+	s.mutex.Lock()
+
 	if s.capacity <= 0 {
+		s.mutex.Unlock()
 		return // для unbounded deque ничего не делаем
 	}
 
-	s.mutex.Lock()
-
-	if float64(s.size()) <= float64(s.capacity)*shrinkFactor {
-		arr := s.values()
-		s.initialCapacity = int(float64(s.capacity) / shrinkCoefficient)
-		s.flush()
-		err = s.processAll(false, s.pushTailInternal, arr...)
+	if c := max(1, s.policy.Shrink(s.size(), s.capacity)); c < s.capacity {
+		err = s.setCapacity(c)
 	}
 
 	s.mutex.Unlock()
@@ -311,6 +491,36 @@ func (s *Deque[T]) Shrink() (err error) {
 	return
 }
 
+// setCapacity - меняет максимальный размер очереди; не поместившиеся элементы
+// вытесняются с головы (в очереди с вытеснением) или отбрасываются с хвоста
+func (s *Deque[T]) setCapacity(capacity int) (err error) {
+	arr := s.values()
+	var evicted []T
+	side := SideHead
+	if len(arr) > capacity {
+		if s.preemption {
+			arr, evicted = arr[len(arr)-capacity:], arr[:len(arr)-capacity]
+		} else {
+			arr, evicted = arr[:capacity], arr[capacity:]
+			slices.Reverse(evicted)
+			side = SideTail
+			err = ErrDequeueFull
+		}
+	}
+	oldCapacity := s.capacity
+	s.initialCapacity = capacity
+	s.capacity = capacity
+	s.items = make([]T, capacity)
+	s.head = 0
+	s.count = copy(s.items, arr)
+	s.observeResize(oldCapacity)
+	for _, item := range evicted {
+		s.evict(item, side)
+	}
+	s.notify()
+	return
+}
+
 // Values - возвращает массив элементов в очереди
 func (s *Deque[T]) Values() []T {
 	s.mutex.RLock()
@@ -319,13 +529,45 @@ func (s *Deque[T]) Values() []T {
 	return v
 }
 func (s *Deque[T]) values() []T {
-	var v []T
-	for _, t := range s.items {
-		v = append(v, t)
+	if s.count == 0 {
+		return nil
 	}
+	v := make([]T, s.count)
+	s.copyTo(v)
 	return v
 }
 
+// All - возвращает итератор по элементам очереди от головы к хвосту. Перебор
+// выполняется "вживую" под блокировкой очереди на чтение, без копирования элементов:
+// тело цикла не должно обращаться к очереди (иначе возможна взаимоблокировка),
+// а пишущие операции ждут завершения перебора. Для перебора с изменением очереди
+// используйте Values().
+func (s *Deque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		for i := 0; i < s.count; i++ {
+			if !yield(s.items[s.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward - возвращает итератор по элементам очереди от хвоста к голове;
+// семантика блокировки такая же, как у All()
+func (s *Deque[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		for i := s.count - 1; i >= 0; i-- {
+			if !yield(s.items[s.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
 // Size - возвращает количество элементов в очереди
 func (s *Deque[T]) Size() int {
 	s.mutex.RLock()
@@ -334,55 +576,469 @@ func (s *Deque[T]) Size() int {
 	return sz
 }
 func (s *Deque[T]) size() int {
-	return len(s.items)
+	return s.count
 }
 
 // Capacity - возвращает максимальный размер очереди; -1 для неограниченной очереди
 func (s *Deque[T]) Capacity() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return s.capacity
 }
 
+// region - either side
+
+// Push - добавляет элементы с заданной стороны очереди (как PushHeadAll/PushTailAll);
+// при вытеснении из заполненной очереди удаляется элемент с противоположной стороны
+func (s *Deque[T]) Push(side Side, items ...T) error {
+	if side == SideHead {
+		return s.PushHeadAll(items...)
+	}
+	return s.PushTailAll(items...)
+}
+
+// PushWait - добавляет элемент с заданной стороны очереди, ожидая освобождения места
+// (как PushHeadWait/PushTailWait)
+func (s *Deque[T]) PushWait(ctx context.Context, side Side, item T) error {
+	if side == SideHead {
+		return s.PushHeadWait(ctx, item)
+	}
+	return s.PushTailWait(ctx, item)
+}
+
+// Pop - извлекает элемент с заданной стороны очереди
+func (s *Deque[T]) Pop(side Side) (T, error) {
+	if side == SideHead {
+		return s.PopHead()
+	}
+	return s.PopTail()
+}
+
+// PopWait - извлекает элемент с заданной стороны очереди, ожидая его появления
+// (как PopHeadWait/PopTailWait)
+func (s *Deque[T]) PopWait(ctx context.Context, side Side) (T, error) {
+	if side == SideHead {
+		return s.PopHeadWait(ctx)
+	}
+	return s.PopTailWait(ctx)
+}
+
+// Peek - возвращает элемент с заданной стороны очереди, не извлекая его
+func (s *Deque[T]) Peek(side Side) (T, error) {
+	if side == SideHead {
+		return s.PeekHead()
+	}
+	return s.PeekTail()
+}
+
+// endregion
+// region - batch operations
+
+// PopHeadN - атомарно извлекает до n элементов из головы очереди; возвращает их
+// в порядке извлечения (пустой срез, если очередь пуста)
+func (s *Deque[T]) PopHeadN(n int) []T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.popN(n, s.popHeadInternal)
+}
+
+// PopTailN - атомарно извлекает до n элементов из хвоста очереди; возвращает их
+// в порядке извлечения (от хвоста к голове)
+func (s *Deque[T]) PopTailN(n int) []T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.popN(n, s.popTailInternal)
+}
+
+// PopHeadWhile - атомарно извлекает элементы из головы очереди, пока для них
+// выполняется условие; возвращает их в порядке извлечения
+func (s *Deque[T]) PopHeadWhile(pred func(T) bool) []T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var v []T
+	for s.count > 0 && pred(s.items[s.head]) {
+		v = append(v, s.popHeadInternal())
+	}
+	return v
+}
+
+// PopTailWhile - атомарно извлекает элементы из хвоста очереди, пока для них
+// выполняется условие; возвращает их в порядке извлечения (от хвоста к голове)
+func (s *Deque[T]) PopTailWhile(pred func(T) bool) []T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var v []T
+	for s.count > 0 && pred(s.items[s.index(s.count-1)]) {
+		v = append(v, s.popTailInternal())
+	}
+	return v
+}
+
+// DrainAll - атомарно извлекает все элементы очереди; возвращает их от головы к хвосту
+func (s *Deque[T]) DrainAll() []T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	v := s.values()
+	s.flush()
+	return v
+}
+
+func (s *Deque[T]) popN(n int, pop func() T) []T {
+	n = min(n, s.count)
+	if n <= 0 {
+		return nil
+	}
+	v := make([]T, n)
+	for i := range v {
+		v[i] = pop()
+	}
+	return v
+}
+
+// endregion
+// region - indexed access
+
+// At - возвращает i-й (считая от головы) элемент очереди
+func (s *Deque[T]) At(i int) (T, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if err := s.checkIndex(i); err != nil {
+		var empty T
+		return empty, err
+	}
+	return s.items[s.index(i)], nil
+}
+
+// Set - заменяет i-й (считая от головы) элемент очереди
+func (s *Deque[T]) Set(i int, item T) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.checkIndex(i); err != nil {
+		return err
+	}
+	s.items[s.index(i)] = item
+	return nil
+}
+
+// InsertAt - вставляет элемент в позицию i (0 - голова, Size() - хвост); элементы,
+// начиная с i-го, сдвигаются к хвосту. В заполненной очереди с вытеснением
+// вытесняется хвостовой элемент (головной - при вставке в позицию Size())
+func (s *Deque[T]) InsertAt(i int, item T) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if i < 0 || i > s.count {
+		return ErrOutOfRange
+	}
+	side := s.nearestSide(i)
+	if err := s.checkPush(); err != nil {
+		return s.reject(err, side)
+	}
+	if i == s.count {
+		s.pushTailInternal(item)
+		return nil
+	}
+	if s.preemption && s.count >= s.capacity {
+		s.evict(s.removeTail(), SideTail)
+	}
+	s.insertAt(i, item)
+	s.observePush(side)
+	s.notify()
+	return nil
+}
+
+// RemoveAt - удаляет и возвращает i-й (считая от головы) элемент очереди
+func (s *Deque[T]) RemoveAt(i int) (T, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.checkIndex(i); err != nil {
+		var empty T
+		return empty, err
+	}
+	side := s.nearestSide(i)
+	item := s.removeAt(i)
+	s.observePop(side)
+	s.autoShrink()
+	s.trim()
+	s.notify()
+	return item, nil
+}
+
+// Swap - меняет местами i-й и j-й элементы очереди
+func (s *Deque[T]) Swap(i, j int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.checkIndex(i); err != nil {
+		return err
+	}
+	if err := s.checkIndex(j); err != nil {
+		return err
+	}
+	x, y := s.index(i), s.index(j)
+	s.items[x], s.items[y] = s.items[y], s.items[x]
+	return nil
+}
+
+func (s *Deque[T]) checkIndex(i int) error {
+	if i < 0 || i >= s.count {
+		return ErrOutOfRange
+	}
+	return nil
+}
+
+// endregion
+// region - reordering and search
+
+// Rotate - циклически сдвигает элементы на n позиций к хвосту: n хвостовых элементов
+// переходят в голову очереди (как collections.deque.rotate); при n < 0 -n головных
+// элементов переходят в хвост
+func (s *Deque[T]) Rotate(n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.count < 2 {
+		return
+	}
+	k := (n%s.count + s.count) % s.count
+	switch {
+	case k == 0:
+	case s.count == len(s.items):
+		// буфер заполнен - достаточно сдвинуть голову
+		s.head = s.index(s.count - k)
+	case k <= s.count/2:
+		for ; k > 0; k-- {
+			item := s.removeTail()
+			s.head = s.index(-1)
+			s.items[s.head] = item
+			s.count++
+		}
+	default:
+		for k = s.count - k; k > 0; k-- {
+			item := s.removeHead()
+			s.items[s.index(s.count)] = item
+			s.count++
+		}
+	}
+}
+
+// Reverse - меняет порядок элементов очереди на обратный
+func (s *Deque[T]) Reverse() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, j := 0, s.count-1; i < j; i, j = i+1, j-1 {
+		x, y := s.index(i), s.index(j)
+		s.items[x], s.items[y] = s.items[y], s.items[x]
+	}
+}
+
+// IndexFunc - возвращает позицию (считая от головы) первого элемента, для которого
+// выполняется условие, или -1; условие проверяется под блокировкой очереди
+func (s *Deque[T]) IndexFunc(pred func(T) bool) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for i := 0; i < s.count; i++ {
+		if pred(s.items[s.index(i)]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ContainsFunc - возвращает true, если в очереди есть элемент, для которого выполняется условие
+func (s *Deque[T]) ContainsFunc(pred func(T) bool) bool {
+	return s.IndexFunc(pred) >= 0
+}
+
+// RemoveFunc - удаляет все элементы, для которых выполняется условие, сохраняя порядок
+// остальных; возвращает количество удалённых элементов
+func (s *Deque[T]) RemoveFunc(pred func(T) bool) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.removeFunc(pred)
+}
+
+// RetainFunc - оставляет в очереди только элементы, для которых выполняется условие;
+// возвращает количество удалённых элементов
+func (s *Deque[T]) RetainFunc(pred func(T) bool) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.removeFunc(func(item T) bool { return !pred(item) })
+}
+
+func (s *Deque[T]) removeFunc(pred func(T) bool) int {
+	var removed []int // позиции удалённых элементов
+	w := 0
+	for r := 0; r < s.count; r++ {
+		item := s.items[s.index(r)]
+		if pred(item) {
+			removed = append(removed, r)
+			continue
+		}
+		s.items[s.index(w)] = item
+		w++
+	}
+	if len(removed) == 0 {
+		return 0
+	}
+	var empty T
+	for i := w; i < s.count; i++ {
+		s.items[s.index(i)] = empty // не удерживаем ссылки на удалённые элементы
+	}
+	if s.observer != nil {
+		for i, r := range removed {
+			side := s.nearestSide(r)
+			s.observer.OnPop(DequeEvent{Side: side, Size: s.count - i - 1, Capacity: s.capacity})
+		}
+	}
+	s.count = w
+	s.autoShrink()
+	s.trim()
+	s.notify()
+	return len(removed)
+}
+
+// endregion
+
 func (s *Deque[T]) pushHeadInternal(item T) {
 
+	// если нужно, вытесняем старый элемент с противоположной стороны
+	if s.preemption && s.count >= s.capacity {
+		s.evict(s.removeTail(), SideTail)
+	}
+
 	// добавляем новый элемент
-	s.items = append([]T{item}, s.items...)
+	s.grow()
+	s.head = s.index(-1)
+	s.items[s.head] = item
+	s.count++
 
-	// если нужно, вытесняем старый
-	if len(s.items) > s.capacity && s.preemption {
-		s.items = s.items[:len(s.items)-1]
-	}
+	s.observePush(SideHead)
+	s.notify()
 
 }
 func (s *Deque[T]) pushTailInternal(item T) {
 
+	// если нужно, вытесняем старый элемент с противоположной стороны
+	if s.preemption && s.count >= s.capacity {
+		s.evict(s.removeHead(), SideHead)
+	}
+
 	// добавляем новый элемент
-	s.items = append(s.items, item)
+	s.grow()
+	s.items[s.index(s.count)] = item
+	s.count++
+
+	s.observePush(SideTail)
+	s.notify()
 
-	// если нужно, вытесняем старый
-	if len(s.items) > s.capacity && s.preemption {
-		s.items = s.items[1:len(s.items)]
+}
+func (s *Deque[T]) pusher(side Side) func(T) {
+	if side == SideHead {
+		return s.pushHeadInternal
 	}
+	return s.pushTailInternal
+}
+func (s *Deque[T]) popper(side Side) func() T {
+	if side == SideHead {
+		return s.popHeadInternal
+	}
+	return s.popTailInternal
+}
 
+// restore - возвращает извлечённый элемент на прежнее место; если места в очереди
+// уже нет, элемент передаётся обработчику вытеснения
+func (s *Deque[T]) restore(item T, side Side) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.capacity > 0 && s.count >= s.capacity {
+		s.evict(item, side)
+		return
+	}
+	s.pusher(side)(item)
+}
+func (s *Deque[T]) evict(item T, side Side) {
+	if s.onEvict != nil {
+		s.onEvict(item, side)
+	}
+	if s.observer != nil {
+		s.observer.OnEvict(s.event(side))
+	}
+}
+func (s *Deque[T]) popHeadInternal() T {
+	item := s.removeHead()
+	s.observePop(SideHead)
+	s.autoShrink()
+	s.trim()
+	s.notify()
+	return item
+}
+func (s *Deque[T]) popTailInternal() T {
+	item := s.removeTail()
+	s.observePop(SideTail)
+	s.autoShrink()
+	s.trim()
+	s.notify()
+	return item
+}
+func (s *Deque[T]) checkPush() error {
+	if s.closed {
+		return ErrClosed
+	}
+	return s.checkSize()
 }
 func (s *Deque[T]) checkSize() error {
-	if s.capacity > 0 && len(s.items) >= s.capacity && !s.preemption {
+	s.autoGrow()
+	if s.capacity > 0 && s.count >= s.capacity && !s.preemption {
 		return ErrDequeueFull
 	}
 	return nil
 }
-func (s *Deque[T]) processAll(reversed bool, receiver func(T), items ...T) (err error) {
+
+// autoGrow/autoShrink - меняют максимальный размер очереди по политике роста
+// (в режиме DequeWithAutoResize)
+func (s *Deque[T]) autoGrow() {
+	if !s.autoResize || s.capacity <= 0 {
+		return
+	}
+	if c := min(s.policy.Grow(s.count, s.capacity), s.maxSize); c > s.capacity {
+		oldCapacity := s.capacity
+		s.capacity, s.initialCapacity = c, c
+		s.observeResize(oldCapacity)
+	}
+}
+func (s *Deque[T]) autoShrink() {
+	if !s.autoResize || s.capacity <= 0 {
+		return
+	}
+	// после удаления нескольких элементов сразу может потребоваться несколько шагов сжатия
+	oldCapacity := s.capacity
+	for {
+		c := max(s.policy.Shrink(s.count, s.capacity), s.count, s.sizeLimit)
+		if c >= s.capacity {
+			break
+		}
+		s.capacity, s.initialCapacity = c, c
+	}
+	s.observeResize(oldCapacity)
+}
+func (s *Deque[T]) pushAll(reversed bool, side Side, items ...T) error {
+	if s.closed {
+		return ErrClosed
+	}
+	return s.processAll(reversed, side, items...)
+}
+func (s *Deque[T]) processAll(reversed bool, side Side, items ...T) (err error) {
 
 	if len(items) == 0 {
 		return nil
 	}
 
+	push := s.pusher(side)
 	if reversed {
 		for i := len(items) - 1; i >= 0; i-- {
 			err = s.checkSize()
 			if err != nil {
 				break
 			}
-			receiver(items[i])
+			push(items[i])
 		}
 	} else {
 		for i := 0; i < len(items); i++ {
@@ -390,10 +1046,241 @@ func (s *Deque[T]) processAll(reversed bool, receiver func(T), items ...T) (err
 			if err != nil {
 				break
 			}
-			receiver(items[i])
+			push(items[i])
 		}
 	}
 
+	return s.reject(err, side)
+
+}
+
+// region - observer
+
+func (s *Deque[T]) event(side Side) DequeEvent {
+	return DequeEvent{
+		Side:     side,
+		Size:     s.count,
+		Capacity: s.capacity,
+	}
+}
+func (s *Deque[T]) observePush(side Side) {
+	if s.observer != nil {
+		s.observer.OnPush(s.event(side))
+	}
+}
+func (s *Deque[T]) observePop(side Side) {
+	if s.observer != nil {
+		s.observer.OnPop(s.event(side))
+	}
+}
+func (s *Deque[T]) observeResize(oldCapacity int) {
+	if s.observer != nil && s.capacity != oldCapacity {
+		s.observer.OnResize(s.event(SideHead), oldCapacity)
+	}
+}
+
+// observeClear - сообщает об извлечении (с головы) всех элементов очищаемой очереди
+func (s *Deque[T]) observeClear() {
+	if s.observer == nil {
+		return
+	}
+	for n := s.count - 1; n >= 0; n-- {
+		s.observer.OnPop(DequeEvent{Side: SideHead, Size: n, Capacity: s.capacity})
+	}
+}
+
+// reject - сообщает об отказе в добавлении в заполненную очередь; возвращает err
+func (s *Deque[T]) reject(err error, side Side) error {
+	if s.observer != nil && errors.Is(err, ErrDequeueFull) {
+		s.observer.OnReject(s.event(side))
+	}
 	return err
+}
+
+// nearestSide - ближайшая к i-й позиции сторона очереди (для событий InsertAt/RemoveAt)
+func (s *Deque[T]) nearestSide(i int) Side {
+	if 2*i < s.count {
+		return SideHead
+	}
+	return SideTail
+}
+
+// endregion
+// region - ring buffer
 
+// index - возвращает позицию в буфере i-го (считая от головы) элемента очереди; i >= -1
+func (s *Deque[T]) index(i int) int {
+	return (s.head + i + len(s.items)) % len(s.items)
+}
+func (s *Deque[T]) removeHead() T {
+	var empty T
+	item := s.items[s.head]
+	s.items[s.head] = empty // не удерживаем ссылку на извлечённый элемент
+	s.head = s.index(1)
+	s.count--
+	return item
+}
+func (s *Deque[T]) removeTail() T {
+	var empty T
+	idx := s.index(s.count - 1)
+	item := s.items[idx]
+	s.items[idx] = empty // не удерживаем ссылку на извлечённый элемент
+	s.count--
+	return item
 }
+
+// insertAt - вставляет элемент в позицию i (0 <= i <= count), сдвигая
+// меньшую из частей очереди
+func (s *Deque[T]) insertAt(i int, item T) {
+	s.grow()
+	if i < s.count/2 {
+		s.head = s.index(-1)
+		for j := 0; j < i; j++ {
+			s.items[s.index(j)] = s.items[s.index(j+1)]
+		}
+	} else {
+		for j := s.count; j > i; j-- {
+			s.items[s.index(j)] = s.items[s.index(j-1)]
+		}
+	}
+	s.items[s.index(i)] = item
+	s.count++
+}
+
+// removeAt - удаляет элемент в позиции i (0 <= i < count), сдвигая
+// меньшую из частей очереди
+func (s *Deque[T]) removeAt(i int) T {
+	item := s.items[s.index(i)]
+	if i < s.count/2 {
+		for j := i; j > 0; j-- {
+			s.items[s.index(j)] = s.items[s.index(j-1)]
+		}
+		s.removeHead()
+	} else {
+		for j := i; j < s.count-1; j++ {
+			s.items[s.index(j)] = s.items[s.index(j+1)]
+		}
+		s.removeTail()
+	}
+	return item
+}
+
+// grow - увеличивает буфер вдвое, если в нём не осталось места
+func (s *Deque[T]) grow() {
+	if s.count < len(s.items) {
+		return
+	}
+	s.resize(max(2*len(s.items), 1))
+}
+
+// trim - уменьшает буфер вдвое, если он заполнен менее чем на четверть
+// (но не меньше начальной ёмкости)
+func (s *Deque[T]) trim() {
+	if len(s.items) <= s.initialCapacity || s.count > len(s.items)/4 {
+		return
+	}
+	s.resize(max(len(s.items)/2, s.initialCapacity))
+}
+func (s *Deque[T]) resize(size int) {
+	items := make([]T, size)
+	s.copyTo(items)
+	s.items = items
+	s.head = 0
+}
+
+// copyTo - копирует элементы очереди (от головы к хвосту) в dst
+func (s *Deque[T]) copyTo(dst []T) {
+	if s.count == 0 {
+		return
+	}
+	n := copy(dst, s.items[s.head:min(s.head+s.count, len(s.items))])
+	copy(dst[n:], s.items[:s.count-n])
+}
+
+// endregion
+// region - waiting
+
+func (s *Deque[T]) popWait(ctx context.Context, pop func() T) (T, error) {
+	for {
+		s.mutex.Lock()
+		if s.count > 0 {
+			item := pop()
+			s.mutex.Unlock()
+			return item, nil
+		}
+		if s.closed {
+			s.mutex.Unlock()
+			var empty T
+			return empty, ErrClosed
+		}
+		changed := s.changed()
+		s.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			var empty T
+			return empty, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+func (s *Deque[T]) pushWait(ctx context.Context, item T, push func(T)) error {
+	for {
+		s.mutex.Lock()
+		err := s.checkPush()
+		if err == nil {
+			push(item)
+			s.mutex.Unlock()
+			return nil
+		}
+		if !errors.Is(err, ErrDequeueFull) {
+			s.mutex.Unlock()
+			return err
+		}
+		changed := s.changed()
+		s.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// changed - возвращает канал, который будет закрыт при следующем изменении очереди;
+// вызывается под блокировкой на запись
+func (s *Deque[T]) changed() <-chan struct{} {
+	if s.signal == nil {
+		s.signal = make(chan struct{})
+	}
+	return s.signal
+}
+
+// notify - будит всех ожидающих изменения очереди и, если закрытая очередь опустела,
+// закрывает канал Done(); вызывается под блокировкой на запись
+func (s *Deque[T]) notify() {
+	if s.signal != nil {
+		close(s.signal)
+		s.signal = nil
+	}
+	if s.closed && s.count == 0 {
+		done := s.doneChan()
+		select {
+		case <-done:
+		default:
+			close(done)
+		}
+	}
+}
+
+// doneChan - возвращает канал Done(), создавая его при первом обращении (в т.ч.
+// для нулевого значения Deque); вызывается под блокировкой на запись
+func (s *Deque[T]) doneChan() chan struct{} {
+	if s.done == nil {
+		s.done = make(chan struct{})
+	}
+	return s.done
+}
+
+// endregion
@@ -0,0 +1,128 @@
+package container
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+)
+
+// dequeSnapshotVersion - версия двоичного формата (первый байт MarshalBinary)
+const dequeSnapshotVersion byte = 1
+
+// dequeSnapshot - сериализуемое состояние очереди: элементы от головы к хвосту
+// и настройки, определяющие её поведение. Размер выделенного буфера не сохраняется:
+// он зависит от истории операций и восстанавливается по количеству элементов.
+type dequeSnapshot[T any] struct {
+	Capacity        int  `json:"capacity"`        // Текущее ограничение размера, Capacity() (-1 для безлимитной очереди)
+	SizeLimit       int  `json:"sizeLimit"`       // Заданное ограничение размера - нижняя граница автоматического сжатия
+	InitialCapacity int  `json:"initialCapacity"` // Ёмкость буфера пустой очереди (после Flush)
+	Preemption      bool `json:"preemption"`      // Вытеснение при добавлении в заполненную очередь
+	Items           []T  `json:"items"`
+}
+
+// MarshalJSON - сохраняет элементы очереди (от головы к хвосту) и её настройки
+// (текущее и заданное ограничение размера, начальную ёмкость буфера, вытеснение);
+// обработчик вытеснения, политика роста и состояние закрытия не сохраняются
+func (s *Deque[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.snapshot())
+}
+
+// UnmarshalJSON - восстанавливает очередь, сохранённую MarshalJSON, заменяя её
+// текущее содержимое и настройки
+func (s *Deque[T]) UnmarshalJSON(data []byte) error {
+	var snap dequeSnapshot[T]
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+	return s.restoreSnapshot(snap)
+}
+
+// MarshalBinary - то же, что MarshalJSON, в двоичном формате (encoding/gob)
+func (s *Deque[T]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(dequeSnapshotVersion)
+	if err := gob.NewEncoder(&buf).Encode(s.snapshot()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary - восстанавливает очередь, сохранённую MarshalBinary
+func (s *Deque[T]) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != dequeSnapshotVersion {
+		return fmt.Errorf("%w: unsupported format version", ErrInvalidSnapshot)
+	}
+	var snap dequeSnapshot[T]
+	if err := gob.NewDecoder(bytes.NewReader(data[1:])).Decode(&snap); err != nil {
+		return err
+	}
+	return s.restoreSnapshot(snap)
+}
+
+func (s *Deque[T]) snapshot() dequeSnapshot[T] {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return dequeSnapshot[T]{
+		Capacity:        s.capacity,
+		SizeLimit:       s.sizeLimit,
+		InitialCapacity: s.initialCapacity,
+		Preemption:      s.preemption,
+		Items:           s.values(),
+	}
+}
+func (s *Deque[T]) restoreSnapshot(snap dequeSnapshot[T]) error {
+
+	if snap.InitialCapacity <= 0 {
+		return fmt.Errorf("%w: initial capacity %d", ErrInvalidSnapshot, snap.InitialCapacity)
+	}
+	if snap.Capacity > 0 && len(snap.Items) > snap.Capacity {
+		return fmt.Errorf("%w: %d items exceed capacity %d", ErrInvalidSnapshot, len(snap.Items), snap.Capacity)
+	}
+	if snap.Capacity > 0 && snap.InitialCapacity > snap.Capacity {
+		return fmt.Errorf("%w: initial capacity %d exceeds capacity %d", ErrInvalidSnapshot, snap.InitialCapacity, snap.Capacity)
+	}
+	if snap.Capacity > 0 && (snap.SizeLimit <= 0 || snap.SizeLimit > snap.Capacity) {
+		return fmt.Errorf("%w: size limit %d does not match capacity %d", ErrInvalidSnapshot, snap.SizeLimit, snap.Capacity)
+	}
+	if snap.Capacity <= 0 {
+		// ёмкость безлимитной очереди берётся из входных данных, поэтому ограничиваем её,
+		// чтобы не выделять память под заведомо лишние элементы
+		snap.Capacity = -1
+		snap.SizeLimit = -1
+		snap.InitialCapacity = min(snap.InitialCapacity, max(8, len(snap.Items)))
+		snap.Preemption = false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.policy == nil {
+		// восстановление в нулевое значение Deque
+		s.policy = DefaultGrowthPolicy()
+	}
+	s.observeClear()
+	oldCapacity := s.capacity
+	s.capacity = snap.Capacity
+	s.sizeLimit = snap.SizeLimit
+	s.initialCapacity = snap.InitialCapacity
+	s.preemption = snap.Preemption
+	s.items = make([]T, max(snap.InitialCapacity, len(snap.Items)))
+	s.head = 0
+	s.count = 0
+	s.observeResize(oldCapacity)
+	for _, item := range snap.Items {
+		s.items[s.count] = item
+		s.count++
+		s.observePush(SideTail)
+	}
+	s.notify()
+
+	return nil
+
+}
@@ -1,9 +1,17 @@
 package container
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"reflect"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -1025,3 +1033,911 @@ func TestDequeExpandShrink(t *testing.T) {
 	assert.Equal(t, 7, v)
 
 }
+
+func TestDequePopWait(t *testing.T) {
+
+	d := NewDeque[int]()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, d.PushTail(1))
+		assert.NoError(t, d.PushTail(2))
+	}()
+
+	v, err := d.PopHeadWait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	v, err = d.PopTailWait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = d.PopHeadWait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+}
+func TestDequePushWait(t *testing.T) {
+
+	d := NewDeque[int](
+		DequeWithSizeLimit(2),
+	)
+	assert.NoError(t, d.PushHeadWait(context.Background(), 1))
+	assert.NoError(t, d.PushTailWait(context.Background(), 2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.PushTailWait(ctx, 3), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		v, err := d.PopHead()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, v)
+	}()
+
+	assert.NoError(t, d.PushTailWait(context.Background(), 3))
+	assert.Equal(t, []int{2, 3}, d.Values())
+
+}
+
+func TestDequeWrapAround(t *testing.T) {
+
+	d := NewDeque[int]()
+
+	// заполняем с обеих сторон, чтобы элементы "перешли" через границу буфера
+	for i := 1; i <= 20; i++ {
+		assert.NoError(t, d.PushHead(-i))
+		assert.NoError(t, d.PushTail(i))
+	}
+	assert.Equal(t, 40, d.Size())
+
+	expected := make([]int, 0, 40)
+	for i := 20; i >= 1; i-- {
+		expected = append(expected, -i)
+	}
+	for i := 1; i <= 20; i++ {
+		expected = append(expected, i)
+	}
+	assert.Equal(t, expected, d.Values())
+
+	for i := 20; i >= 1; i-- {
+		v, err := d.PopHead()
+		assert.NoError(t, err)
+		assert.Equal(t, -i, v)
+	}
+	for i := 20; i >= 1; i-- {
+		v, err := d.PopTail()
+		assert.NoError(t, err)
+		assert.Equal(t, i, v)
+	}
+	assert.Equal(t, 0, d.Size())
+	assert.Nil(t, d.Values())
+
+	d.Flush()
+	assert.Equal(t, -1, d.Capacity())
+
+}
+
+func TestDequeEvictionHandler(t *testing.T) {
+
+	type eviction struct {
+		item int
+		side Side
+	}
+	var evicted []eviction
+
+	d := NewDeque[int](
+		DequeWithSizeLimit(3),
+		DequeWithPreemption(),
+		DequeWithEvictionHandler(func(item int, side Side) {
+			evicted = append(evicted, eviction{item, side})
+		}),
+	)
+
+	assert.NoError(t, d.PushTailAll(1, 2, 3, 4))
+	assert.NoError(t, d.PushHead(5))
+	assert.Equal(t, []int{5, 2, 3}, d.Values())
+	assert.Equal(t, []eviction{{1, SideHead}, {4, SideTail}}, evicted)
+
+	evicted = nil
+	d = NewDeque[int](
+		DequeWithSizeLimit(10),
+		DequeWithEvictionHandler(func(item int, side Side) {
+			evicted = append(evicted, eviction{item, side})
+		}),
+	)
+	assert.NoError(t, d.PushTailAll(1, 2, 3, 4, 5, 6, 7, 8, 9, 10))
+	assert.ErrorIs(t, d.Shrink(), ErrDequeueFull)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, d.Values())
+	assert.Equal(t, []eviction{{10, SideTail}, {9, SideTail}}, evicted)
+
+	assert.Panics(t, func() {
+		NewDeque[string](DequeWithEvictionHandler(func(item int, side Side) {}))
+	})
+
+}
+
+func TestDequeIndexedAccess(t *testing.T) {
+
+	d := NewDeque[int]()
+	assert.NoError(t, d.PushTailAll(1, 2, 3, 4, 5))
+	assert.NoError(t, d.PushHead(0))
+
+	v, err := d.At(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, v)
+	v, err = d.At(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, v)
+	_, err = d.At(6)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	_, err = d.At(-1)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+
+	assert.NoError(t, d.Set(2, 20))
+	assert.ErrorIs(t, d.Set(6, 60), ErrOutOfRange)
+	assert.Equal(t, []int{0, 1, 20, 3, 4, 5}, d.Values())
+
+	assert.NoError(t, d.InsertAt(1, 10))
+	assert.NoError(t, d.InsertAt(5, 40))
+	assert.NoError(t, d.InsertAt(8, 50))
+	assert.ErrorIs(t, d.InsertAt(10, 0), ErrOutOfRange)
+	assert.Equal(t, []int{0, 10, 1, 20, 3, 40, 4, 5, 50}, d.Values())
+
+	v, err = d.RemoveAt(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, v)
+	v, err = d.RemoveAt(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 40, v)
+	_, err = d.RemoveAt(7)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	assert.Equal(t, []int{0, 1, 20, 3, 4, 5, 50}, d.Values())
+
+	assert.NoError(t, d.Swap(0, 6))
+	assert.ErrorIs(t, d.Swap(0, 7), ErrOutOfRange)
+	assert.Equal(t, []int{50, 1, 20, 3, 4, 5, 0}, d.Values())
+
+}
+func TestBoundedDequeInsertAt(t *testing.T) {
+
+	d := NewDeque[int](
+		DequeWithSizeLimit(3),
+	)
+	assert.NoError(t, d.PushTailAll(1, 2, 3))
+	assert.ErrorIs(t, d.InsertAt(1, 10), ErrDequeueFull)
+	assert.Equal(t, []int{1, 2, 3}, d.Values())
+
+	d = NewDeque[int](
+		DequeWithSizeLimit(3),
+		DequeWithPreemption(),
+	)
+	assert.NoError(t, d.PushTailAll(1, 2, 3))
+	assert.NoError(t, d.InsertAt(1, 10))
+	assert.Equal(t, []int{1, 10, 2}, d.Values())
+	assert.NoError(t, d.InsertAt(3, 20))
+	assert.Equal(t, []int{10, 2, 20}, d.Values())
+
+}
+
+func TestDequeIterators(t *testing.T) {
+
+	d := DequeFrom(slices.Values([]int{1, 2, 3, 4, 5}))
+	assert.Equal(t, 5, d.Size())
+	assert.Equal(t, -1, d.Capacity())
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, slices.Collect(d.All()))
+	assert.Equal(t, []int{5, 4, 3, 2, 1}, slices.Collect(d.Backward()))
+
+	var head []int
+	for v := range d.All() {
+		if v > 2 {
+			break
+		}
+		head = append(head, v)
+	}
+	assert.Equal(t, []int{1, 2}, head)
+
+	// после прерванного перебора очередь доступна на запись
+	assert.NoError(t, d.PushHead(0))
+
+	d = DequeFrom(slices.Values([]int{1, 2, 3, 4, 5}), DequeWithSizeLimit(3))
+	assert.Equal(t, []int{1, 2, 3}, d.Values())
+
+	d = DequeFrom(slices.Values([]int{1, 2, 3, 4, 5}), DequeWithSizeLimit(3), DequeWithPreemption())
+	assert.Equal(t, []int{3, 4, 5}, d.Values())
+
+}
+
+func TestDequeClose(t *testing.T) {
+
+	d := NewDeque[int](
+		DequeWithSizeLimit(2),
+	)
+	assert.NoError(t, d.PushTailAll(1, 2))
+
+	pushed := make(chan error)
+	go func() {
+		pushed <- d.PushTailWait(context.Background(), 3)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	d.Close()
+	d.Close()
+	assert.True(t, d.Closed())
+	assert.ErrorIs(t, <-pushed, ErrClosed)
+
+	assert.ErrorIs(t, d.PushHead(0), ErrClosed)
+	assert.ErrorIs(t, d.PushTailAll(3, 4), ErrClosed)
+	assert.ErrorIs(t, d.InsertAt(0, 0), ErrClosed)
+	assert.ErrorIs(t, d.Replace(3, 4), ErrClosed)
+
+	select {
+	case <-d.Done():
+		assert.Fail(t, "closed deque is not drained yet")
+	default:
+	}
+
+	v, err := d.PopHeadWait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, err = d.PopTail()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	select {
+	case <-d.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "drained deque is not done")
+	}
+
+	_, err = d.PopHeadWait(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+
+}
+func TestDequeCloseWakesWaiters(t *testing.T) {
+
+	d := NewDeque[int]()
+
+	popped := make(chan error)
+	go func() {
+		_, err := d.PopTailWait(context.Background())
+		popped <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	d.Close()
+	assert.ErrorIs(t, <-popped, ErrClosed)
+	<-d.Done()
+
+}
+
+func TestDequeBatchPop(t *testing.T) {
+
+	d := NewDeque[int]()
+	assert.NoError(t, d.PushTailAll(1, 2, 3, 4, 5, 6, 7, 8, 9, 10))
+
+	assert.Equal(t, []int{1, 2, 3}, d.PopHeadN(3))
+	assert.Equal(t, []int{10, 9}, d.PopTailN(2))
+	assert.Nil(t, d.PopHeadN(0))
+
+	assert.Equal(t, []int{4, 5}, d.PopHeadWhile(func(v int) bool { return v < 6 }))
+	assert.Equal(t, []int{8, 7}, d.PopTailWhile(func(v int) bool { return v > 6 }))
+	assert.Nil(t, d.PopHeadWhile(func(v int) bool { return false }))
+	assert.Equal(t, []int{6}, d.Values())
+
+	assert.Equal(t, []int{6}, d.PopTailN(10))
+	assert.Nil(t, d.PopHeadN(1))
+
+	assert.NoError(t, d.PushTailAll(1, 2, 3))
+	assert.Equal(t, []int{1, 2, 3}, d.DrainAll())
+	assert.Equal(t, 0, d.Size())
+	assert.Nil(t, d.DrainAll())
+
+	// нулевое значение Deque закрывается так же, как созданное NewDeque
+	var z Deque[int]
+	assert.NoError(t, z.PushTail(1))
+	done := z.Done()
+	z.Close()
+	select {
+	case <-done:
+		t.Fatal("done closed while deque is not empty")
+	default:
+	}
+	v, err := z.PopHeadWait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	<-z.Done()
+	_, err = z.PopHeadWait(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+
+	var empty Deque[int]
+	empty.Close()
+	<-empty.Done()
+
+}
+
+func TestDequeMarshal(t *testing.T) {
+
+	d := NewDeque[string](
+		DequeWithSizeLimit(5),
+		DequeWithPreemption(),
+	)
+	// смещаем голову кольцевого буфера, чтобы элементы "перешли" через его край
+	assert.NoError(t, d.PushTailAll("x", "y", "a", "b"))
+	d.PopHeadN(2)
+	assert.NoError(t, d.PushTailAll("c", "d"))
+	assert.NoError(t, d.PushHead("z"))
+
+	data, err := json.Marshal(d)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"capacity":5,"sizeLimit":5,"initialCapacity":5,"preemption":true,"items":["z","a","b","c","d"]}`, string(data))
+
+	check := func(t *testing.T, restored *Deque[string]) {
+		assert.Equal(t, d.Values(), restored.Values())
+		assert.Equal(t, d.Capacity(), restored.Capacity())
+		// вытеснение сохранилось
+		assert.NoError(t, restored.PushTail("e"))
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, restored.Values())
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var restored Deque[string]
+		assert.NoError(t, json.Unmarshal(data, &restored))
+		check(t, &restored)
+	})
+	t.Run("binary", func(t *testing.T) {
+		bin, err := d.MarshalBinary()
+		assert.NoError(t, err)
+		restored := NewDeque[string]()
+		assert.NoError(t, restored.PushTail("replaced"))
+		assert.NoError(t, restored.UnmarshalBinary(bin))
+		check(t, restored)
+	})
+	t.Run("expanded", func(t *testing.T) {
+		e := NewDeque[int](DequeWithSizeLimit(4))
+		assert.NoError(t, e.PushTailAll(1, 2, 3))
+		assert.NoError(t, e.Expand())
+		bin, err := e.MarshalBinary()
+		assert.NoError(t, err)
+		var restored Deque[int]
+		assert.NoError(t, restored.UnmarshalBinary(bin))
+		assert.Equal(t, e.Capacity(), restored.Capacity())
+		assert.Equal(t, 5, restored.Capacity())
+		assert.ErrorIs(t, restored.PushTailAll(4, 5, 6), ErrDequeueFull)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, restored.Values())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var restored Deque[int]
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"capacity":2,"sizeLimit":2,"initialCapacity":2,"items":[1,2,3]}`), &restored), ErrInvalidSnapshot)
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"capacity":-1,"initialCapacity":0}`), &restored), ErrInvalidSnapshot)
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"capacity":4,"sizeLimit":4,"initialCapacity":1000000000000000}`), &restored), ErrInvalidSnapshot)
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"capacity":4,"sizeLimit":8,"initialCapacity":4}`), &restored), ErrInvalidSnapshot)
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"capacity":4,"initialCapacity":4}`), &restored), ErrInvalidSnapshot)
+		assert.ErrorIs(t, restored.UnmarshalBinary([]byte{0}), ErrInvalidSnapshot)
+		assert.Error(t, json.Unmarshal([]byte(`{"items":"x"}`), &restored))
+	})
+	t.Run("huge unbounded capacity", func(t *testing.T) {
+		var restored Deque[int]
+		assert.NoError(t, json.Unmarshal([]byte(`{"capacity":-1,"initialCapacity":1000000000000000,"items":[1,2]}`), &restored))
+		assert.Equal(t, []int{1, 2}, restored.Values())
+		assert.Equal(t, 8, len(restored.items))
+	})
+	t.Run("auto-resize floor", func(t *testing.T) {
+		// нижняя граница автоматического сжатия берётся из снимка, а не из опций очереди
+		restored := NewDeque[int](DequeWithSizeLimit(2), DequeWithAutoResize(16))
+		assert.NoError(t, json.Unmarshal([]byte(`{"capacity":8,"sizeLimit":8,"initialCapacity":8,"items":[1,2]}`), restored))
+		_, err := restored.PopHead()
+		assert.NoError(t, err)
+		assert.Equal(t, 8, restored.Capacity())
+	})
+	t.Run("auto-resized", func(t *testing.T) {
+		// сохраняются и расширенное ограничение размера, и заданное (до которого
+		// восстановленная очередь может снова сжаться)
+		a := NewDeque[int](DequeWithSizeLimit(4), DequeWithAutoResize(64))
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, a.PushTail(i))
+		}
+		assert.Greater(t, a.Capacity(), 20)
+		data, err := json.Marshal(a)
+		assert.NoError(t, err)
+
+		restored := NewDeque[int](DequeWithSizeLimit(4), DequeWithAutoResize(64))
+		assert.NoError(t, json.Unmarshal(data, restored))
+		assert.Equal(t, a.Capacity(), restored.Capacity())
+		assert.Equal(t, a.Values(), restored.Values())
+		restored.PopHeadN(19)
+		assert.Equal(t, 4, restored.Capacity())
+	})
+	t.Run("unbounded into auto-resize", func(t *testing.T) {
+		restored := NewDeque[int](DequeWithSizeLimit(4), DequeWithAutoResize(64))
+		assert.NoError(t, json.Unmarshal([]byte(`{"capacity":-1,"initialCapacity":8,"items":[1,2,3,4,5]}`), restored))
+		assert.NoError(t, restored.PushTail(6))
+		assert.Equal(t, -1, restored.Capacity())
+	})
+
+}
+
+func TestDequeAutoResize(t *testing.T) {
+
+	d := NewDeque[int](
+		DequeWithSizeLimit(4),
+		DequeWithAutoResize(32),
+		DequeWithGrowthPolicy(GeometricGrowth{GrowAt: 1, GrowBy: 2, ShrinkAt: 0.25, ShrinkBy: 2, Max: 16}),
+	)
+
+	// очередь расширяется по мере заполнения, но не больше Max
+	for i := 0; i < 16; i++ {
+		assert.NoError(t, d.PushTail(i))
+	}
+	assert.Equal(t, 16, d.Capacity())
+	assert.ErrorIs(t, d.PushTail(16), ErrDequeueFull)
+
+	// и сжимается по мере опустошения, но не меньше заданного размера
+	assert.Len(t, d.PopHeadN(12), 12)
+	assert.Equal(t, 8, d.Capacity())
+	assert.Len(t, d.PopHeadN(4), 4)
+	assert.Equal(t, 4, d.Capacity())
+
+	// Expand/Shrink - явное изменение размера по той же политике
+	assert.NoError(t, d.PushTailAll(1, 2, 3, 4))
+	assert.Equal(t, 4, d.Capacity())
+	assert.NoError(t, d.Expand())
+	assert.Equal(t, 8, d.Capacity())
+	assert.Equal(t, []int{1, 2, 3, 4}, d.Values())
+
+	// с политикой по умолчанию (без Max) расширение ограничено maxSize; дальше очередь
+	// отказывает в добавлении или вытесняет элементы
+	for _, preemption := range []bool{false, true} {
+		opts := []DequeOption{DequeWithSizeLimit(4), DequeWithAutoResize(8)}
+		if preemption {
+			opts = append(opts, DequeWithPreemption())
+		}
+		b := NewDeque[int](opts...)
+		for i := 0; i < 8; i++ {
+			assert.NoError(t, b.PushTail(i))
+		}
+		assert.Equal(t, 8, b.Capacity())
+		if preemption {
+			assert.NoError(t, b.PushTail(8))
+			assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, b.Values())
+		} else {
+			assert.ErrorIs(t, b.PushTail(8), ErrDequeueFull)
+		}
+		assert.Equal(t, 8, b.Capacity())
+	}
+
+	// при чередовании добавления и извлечения размер не меняется: между порогами
+	// расширения и сжатия политики по умолчанию есть зазор
+	c := NewDequeCounter()
+	a := NewDeque[int](DequeWithSizeLimit(4), DequeWithAutoResize(64), DequeWithObserver(c))
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, a.PushTail(i))
+	}
+	capacity, resizes := a.Capacity(), c.Stats().Resizes
+	assert.Greater(t, capacity, 20)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, a.PushTail(i))
+		_, err := a.PopHead()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, capacity, a.Capacity())
+	assert.Equal(t, resizes, c.Stats().Resizes)
+
+	// удаление элементов (не только Pop) тоже сжимает автоматически расширенную очередь,
+	// а Flush и DrainAll возвращают её к заданному ограничению размера
+	grown := func() *Deque[int] {
+		g := NewDeque[int](DequeWithSizeLimit(4), DequeWithAutoResize(64))
+		for i := 0; i < 32; i++ {
+			assert.NoError(t, g.PushTail(i))
+		}
+		assert.Greater(t, g.Capacity(), 32)
+		return g
+	}
+	g := grown()
+	assert.Equal(t, 31, g.RemoveFunc(func(v int) bool { return v > 0 }))
+	assert.Equal(t, 4, g.Capacity())
+	g = grown()
+	for g.Size() > 1 {
+		_, err := g.RemoveAt(g.Size() / 2)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 4, g.Capacity())
+	g = grown()
+	g.Flush()
+	assert.Equal(t, 4, g.Capacity())
+	g = grown()
+	assert.Len(t, g.DrainAll(), 32)
+	assert.Equal(t, 4, g.Capacity())
+
+	// maxSize меньше DequeWithSizeLimit - очередь не расширяется
+	f := NewDeque[int](DequeWithSizeLimit(2), DequeWithAutoResize(0))
+	assert.NoError(t, f.PushTailAll(1, 2))
+	assert.ErrorIs(t, f.PushTail(3), ErrDequeueFull)
+	assert.Equal(t, 2, f.Capacity())
+
+	// без автоматического режима заполненная очередь не расширяется
+	m := NewDeque[int](DequeWithSizeLimit(2), DequeWithGrowthPolicy(FixedGrowth(2, 0)))
+	assert.NoError(t, m.PushTailAll(1, 2))
+	assert.ErrorIs(t, m.PushTail(3), ErrDequeueFull)
+	assert.NoError(t, m.Expand())
+	assert.Equal(t, 4, m.Capacity())
+	assert.NoError(t, m.PushTail(3))
+
+}
+
+func TestDequeRotateReverse(t *testing.T) {
+
+	// ротация сравнивается с эталоном на срезе: при заполненном и незаполненном
+	// буфере, со сдвигом головы через край буфера
+	for _, limit := range []int{5, 8} {
+		for _, n := range []int{-7, -3, -1, 0, 1, 2, 3, 5, 12} {
+			d := NewDeque[int](DequeWithSizeLimit(limit))
+			assert.NoError(t, d.PushTailAll(0, 0, 0))
+			d.PopHeadN(3)
+			assert.NoError(t, d.PushTailAll(1, 2, 3, 4, 5))
+
+			expected := []int{1, 2, 3, 4, 5}
+			k := (n%5 + 5) % 5
+			expected = append(expected[5-k:], expected[:5-k]...)
+
+			d.Rotate(n)
+			assert.Equal(t, expected, d.Values(), "limit %d, n %d", limit, n)
+		}
+	}
+
+	d := NewDeque[int]()
+	d.Rotate(3)
+	d.Reverse()
+	assert.Nil(t, d.Values())
+	assert.NoError(t, d.PushHeadAll(3, 2, 1))
+	d.Reverse()
+	assert.Equal(t, []int{3, 2, 1}, d.Values())
+	assert.NoError(t, d.PushTail(4))
+	d.Reverse()
+	assert.Equal(t, []int{4, 1, 2, 3}, d.Values())
+
+}
+func TestDequeSearch(t *testing.T) {
+
+	d := NewDeque[int]()
+	assert.NoError(t, d.PushTailAll(1, 2, 3, 4, 5, 6, 7, 8, 9, 10))
+
+	even := func(v int) bool { return v%2 == 0 }
+	assert.Equal(t, 1, d.IndexFunc(even))
+	assert.Equal(t, -1, d.IndexFunc(func(v int) bool { return v > 10 }))
+	assert.True(t, d.ContainsFunc(func(v int) bool { return v == 10 }))
+	assert.False(t, d.ContainsFunc(func(v int) bool { return v == 0 }))
+
+	assert.Equal(t, 5, d.RemoveFunc(even))
+	assert.Equal(t, []int{1, 3, 5, 7, 9}, d.Values())
+	assert.Equal(t, 0, d.RemoveFunc(even))
+
+	assert.Equal(t, 2, d.RetainFunc(func(v int) bool { return v > 4 }))
+	assert.Equal(t, []int{5, 7, 9}, d.Values())
+	assert.Equal(t, 3, d.RetainFunc(func(int) bool { return false }))
+	assert.Equal(t, 0, d.Size())
+
+}
+func TestDequeSides(t *testing.T) {
+
+	assert.Equal(t, SideTail, SideHead.Opposite())
+	assert.Equal(t, SideHead, SideTail.Opposite())
+
+	d := NewDeque[int](DequeWithSizeLimit(4), DequeWithPreemption())
+	assert.NoError(t, d.Push(SideTail, 1, 2, 3))
+	assert.NoError(t, d.Push(SideHead, 0, -1)) // 3 вытесняется с противоположной стороны
+	assert.Equal(t, []int{-1, 0, 1, 2}, d.Values())
+
+	for _, side := range []Side{SideHead, SideTail} {
+		v, err := d.Peek(side)
+		assert.NoError(t, err)
+		p, err := d.Pop(side)
+		assert.NoError(t, err)
+		assert.Equal(t, v, p, side.String())
+	}
+	assert.Equal(t, []int{0, 1}, d.Values())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, d.PushWait(ctx, SideTail, 5))
+	v, err := d.PopWait(ctx, SideTail)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, v)
+
+}
+
+func TestBoundedDequeNeverOverflows(t *testing.T) {
+	testBoundedNeverOverflows(t, func(opts ...DequeOption) concurrentIntDeque {
+		return NewDeque[int](opts...)
+	})
+}
+func TestDequeLinearizability(t *testing.T) {
+	testLinearizability(t, func(opts ...DequeOption) concurrentIntDeque {
+		return NewDeque[int](opts...)
+	})
+}
+
+// region - concurrency harness
+
+// concurrentIntDeque - операции, проверяемые на корректность при конкурентном доступе
+type concurrentIntDeque interface {
+	PushHead(int) error
+	PushTail(int) error
+	PopHead() (int, error)
+	PopTail() (int, error)
+	Size() int
+}
+
+func testBoundedNeverOverflows(t *testing.T, newDeque func(opts ...DequeOption) concurrentIntDeque) {
+
+	// гонки проявляются только при параллельном исполнении горутин
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.NumCPU())))
+
+	const (
+		limit   = 16
+		pushers = 32
+		pushes  = 100
+	)
+
+	for _, preemption := range []bool{false, true} {
+		t.Run(fmt.Sprintf("preemption=%v", preemption), func(t *testing.T) {
+
+			opts := []DequeOption{DequeWithSizeLimit(limit)}
+			if preemption {
+				opts = append(opts, DequeWithPreemption())
+			}
+			d := newDeque(opts...)
+
+			var accepted atomic.Int64
+			var overflow atomic.Bool
+			var wg sync.WaitGroup
+			for p := 0; p < pushers; p++ {
+				wg.Add(1)
+				go func(p int) {
+					defer wg.Done()
+					for i := 0; i < pushes; i++ {
+						var err error
+						if i%2 == 0 {
+							err = d.PushHead(p*pushes + i)
+						} else {
+							err = d.PushTail(p*pushes + i)
+						}
+						if err == nil {
+							accepted.Add(1)
+						}
+						if d.Size() > limit {
+							overflow.Store(true)
+						}
+					}
+				}(p)
+			}
+			wg.Wait()
+
+			assert.False(t, overflow.Load())
+			assert.Equal(t, limit, d.Size())
+			if !preemption {
+				assert.Equal(t, int64(limit), accepted.Load())
+			}
+
+			// конкурирующие извлечения не должны получить один и тот же элемент дважды
+			seen := make(map[int]int)
+			var mutex sync.Mutex
+			for p := 0; p < pushers; p++ {
+				wg.Add(1)
+				go func(p int) {
+					defer wg.Done()
+					for {
+						var v int
+						var err error
+						if p%2 == 0 {
+							v, err = d.PopHead()
+						} else {
+							v, err = d.PopTail()
+						}
+						if err != nil {
+							return
+						}
+						mutex.Lock()
+						seen[v]++
+						mutex.Unlock()
+					}
+				}(p)
+			}
+			wg.Wait()
+
+			assert.Len(t, seen, limit)
+			for v, n := range seen {
+				assert.Equal(t, 1, n, "item %d popped %d times", v, n)
+			}
+
+		})
+	}
+
+}
+
+type dequeOpKind int
+
+const (
+	opPushHead dequeOpKind = iota
+	opPushTail
+	opPopHead
+	opPopTail
+)
+
+// dequeOp - запись об операции в истории: аргумент/результат и "время" вызова и возврата
+type dequeOp struct {
+	kind   dequeOpKind
+	value  int
+	err    error
+	call   int64
+	result int64
+}
+
+// dequeModel - последовательная модель ограниченной очереди без вытеснения
+type dequeModel struct {
+	limit int
+}
+
+// apply - применяет операцию к состоянию модели; возвращает новое состояние и признак
+// того, что результат операции в истории совпадает с результатом модели
+func (m dequeModel) apply(state []int, op dequeOp) ([]int, bool) {
+	switch op.kind {
+	case opPushHead, opPushTail:
+		if len(state) >= m.limit {
+			return state, errors.Is(op.err, ErrDequeueFull)
+		}
+		if op.err != nil {
+			return state, false
+		}
+		if op.kind == opPushHead {
+			return append([]int{op.value}, state...), true
+		}
+		return append(slices.Clone(state), op.value), true
+	default:
+		if len(state) == 0 {
+			return state, errors.Is(op.err, ErrDequeueEmpty)
+		}
+		if op.err != nil {
+			return state, false
+		}
+		if op.kind == opPopHead {
+			return state[1:], op.value == state[0]
+		}
+		return state[:len(state)-1], op.value == state[len(state)-1]
+	}
+}
+
+// linearizable - проверяет историю (не более 64 операций) поиском с возвратом
+// (алгоритм Wing & Gong с запоминанием посещённых состояний)
+func (m dequeModel) linearizable(history []dequeOp) bool {
+	all := uint64(1)<<len(history) - 1
+	visited := make(map[string]struct{})
+	var search func(done uint64, state []int) bool
+	search = func(done uint64, state []int) bool {
+		if done == all {
+			return true
+		}
+		key := fmt.Sprint(done, state)
+		if _, ok := visited[key]; ok {
+			return false
+		}
+		visited[key] = struct{}{}
+		// линеаризовать следующей можно любую операцию, вызванную до завершения
+		// самой ранней из оставшихся
+		minResult := int64(math.MaxInt64)
+		for i, op := range history {
+			if done&(1<<i) == 0 {
+				minResult = min(minResult, op.result)
+			}
+		}
+		for i, op := range history {
+			if done&(1<<i) != 0 || op.call > minResult {
+				continue
+			}
+			if next, ok := m.apply(state, op); ok && search(done|1<<i, next) {
+				return true
+			}
+		}
+		return false
+	}
+	return search(0, nil)
+}
+
+func testLinearizability(t *testing.T, newDeque func(opts ...DequeOption) concurrentIntDeque) {
+
+	// гонки проявляются только при параллельном исполнении горутин
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.NumCPU())))
+
+	const (
+		limit   = 3
+		workers = 4
+		ops     = 5
+		rounds  = 200
+	)
+
+	model := dequeModel{limit: limit}
+	for round := 0; round < rounds; round++ {
+
+		d := newDeque(DequeWithSizeLimit(limit))
+
+		var clock atomic.Int64
+		history := make([][]dequeOp, workers)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < ops; i++ {
+					op := dequeOp{kind: dequeOpKind((w + i) % 4), value: w*ops + i}
+					op.call = clock.Add(1)
+					switch op.kind {
+					case opPushHead:
+						op.err = d.PushHead(op.value)
+					case opPushTail:
+						op.err = d.PushTail(op.value)
+					case opPopHead:
+						op.value, op.err = d.PopHead()
+					case opPopTail:
+						op.value, op.err = d.PopTail()
+					}
+					op.result = clock.Add(1)
+					history[w] = append(history[w], op)
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		if !model.linearizable(slices.Concat(history...)) {
+			t.Fatalf("round %d: history is not linearizable: %+v", round, history)
+		}
+
+	}
+
+}
+
+// endregion
+
+func BenchmarkDequeHead(b *testing.B) {
+	for _, size := range []int{100, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			d := NewDeque[int]()
+			for i := 0; i < size; i++ {
+				_ = d.PushTail(i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = d.PushHead(i)
+				_, _ = d.PopHead()
+			}
+		})
+	}
+}
+func BenchmarkDequeTail(b *testing.B) {
+	for _, size := range []int{100, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			d := NewDeque[int]()
+			for i := 0; i < size; i++ {
+				_ = d.PushTail(i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = d.PushTail(i)
+				_, _ = d.PopTail()
+			}
+		})
+	}
+}
+func BenchmarkDequeQueue(b *testing.B) {
+	d := NewDeque[int]()
+	for i := 0; i < 1000; i++ {
+		_ = d.PushTail(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = d.PushTail(i)
+		_, _ = d.PopHead()
+	}
+}
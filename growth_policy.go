@@ -0,0 +1,99 @@
+package container
+
+// GrowthPolicy - стратегия изменения ограничения размера очереди: применяется в
+// Expand/Shrink, а в режиме DequeWithAutoResize - при каждом добавлении и извлечении
+// элемента. Для собственной стратегии достаточно реализовать этот интерфейс.
+type GrowthPolicy interface {
+	// Grow - возвращает новое ограничение размера очереди из size элементов с текущим
+	// ограничением capacity; значение, не превышающее capacity, означает "не расширять"
+	Grow(size, capacity int) int
+	// Shrink - возвращает новое ограничение размера очереди; значение не меньше
+	// capacity означает "не сжимать"
+	Shrink(size, capacity int) int
+}
+
+// GeometricGrowth - расширение и сжатие в заданное число раз; коэффициент, не
+// превышающий 1, отключает соответствующее направление (нулевое значение
+// GeometricGrowth{} не меняет размер очереди)
+type GeometricGrowth struct {
+	GrowAt   float64 // Доля заполнения, начиная с которой очередь расширяется
+	GrowBy   float64 // Коэффициент расширения (больше 1)
+	ShrinkAt float64 // Доля заполнения, начиная с которой (и ниже) очередь сжимается
+	ShrinkBy float64 // Коэффициент сжатия (больше 1)
+	Max      int     // Максимальное ограничение размера (0 - без ограничения)
+}
+
+// DefaultGrowthPolicy - политика по умолчанию: расширение в 1.25 раза при заполнении
+// на 3/4 и сжатие в 1.25 раза (Shrink сжимает очередь при любом заполнении)
+func DefaultGrowthPolicy() GrowthPolicy {
+	return GeometricGrowth{
+		GrowAt:   expandFactor,
+		GrowBy:   expandCoefficient,
+		ShrinkAt: shrinkFactor,
+		ShrinkBy: shrinkCoefficient,
+	}
+}
+
+// AutoResizeGrowthPolicy - политика по умолчанию в режиме DequeWithAutoResize: как
+// DefaultGrowthPolicy, но очередь сжимается только при заполнении не больше чем
+// на 3/8 (половина порога расширения). Без такого зазора между порогами чередование
+// добавления и извлечения расширяло бы и сжимало очередь на каждой операции.
+func AutoResizeGrowthPolicy() GrowthPolicy {
+	return GeometricGrowth{
+		GrowAt:   expandFactor,
+		GrowBy:   expandCoefficient,
+		ShrinkAt: expandFactor / 2,
+		ShrinkBy: shrinkCoefficient,
+	}
+}
+
+func (p GeometricGrowth) Grow(size, capacity int) int {
+	if p.GrowBy <= 1 || float64(size) < float64(capacity)*p.GrowAt {
+		return capacity
+	}
+	// для маленьких очередей рост хотя бы на один элемент
+	return p.limit(max(capacity+1, int(float64(capacity)*p.GrowBy)))
+}
+func (p GeometricGrowth) Shrink(size, capacity int) int {
+	if p.ShrinkBy <= 1 || float64(size) > float64(capacity)*p.ShrinkAt {
+		return capacity
+	}
+	return max(1, int(float64(capacity)/p.ShrinkBy))
+}
+func (p GeometricGrowth) limit(capacity int) int {
+	if p.Max > 0 {
+		return min(capacity, p.Max)
+	}
+	return capacity
+}
+
+type fixedGrowth struct {
+	step int
+	max  int
+}
+
+// FixedGrowth - расширение заполненной очереди на step элементов (но не больше чем
+// до maxCapacity; 0 - без ограничения) и сжатие на step элементов, когда в очереди
+// освобождается больше 2*step мест
+func FixedGrowth(step, maxCapacity int) GrowthPolicy {
+	return fixedGrowth{
+		step: max(1, step),
+		max:  maxCapacity,
+	}
+}
+
+func (p fixedGrowth) Grow(size, capacity int) int {
+	if size < capacity {
+		return capacity
+	}
+	if p.max > 0 {
+		return min(capacity+p.step, p.max)
+	}
+	return capacity + p.step
+}
+func (p fixedGrowth) Shrink(size, capacity int) int {
+	if size > capacity-2*p.step {
+		return capacity
+	}
+	return max(1, capacity-p.step)
+}
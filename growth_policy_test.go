@@ -0,0 +1,48 @@
+package container
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGrowthPolicies(t *testing.T) {
+
+	p := DefaultGrowthPolicy()
+	assert.Equal(t, 8, p.Grow(5, 8))
+	assert.Equal(t, 10, p.Grow(6, 8))
+	assert.Equal(t, 3, p.Grow(2, 2)) // рост хотя бы на один элемент
+	assert.Equal(t, 6, p.Shrink(8, 8))
+	assert.Equal(t, 1, p.Shrink(1, 1))
+
+	g := GeometricGrowth{GrowAt: 1, GrowBy: 2, ShrinkAt: 0.25, ShrinkBy: 2, Max: 12}
+	assert.Equal(t, 8, g.Grow(7, 8))
+	assert.Equal(t, 12, g.Grow(8, 8))
+	assert.Equal(t, 12, g.Grow(12, 12))
+	assert.Equal(t, 8, g.Shrink(3, 8))
+	assert.Equal(t, 4, g.Shrink(2, 8))
+
+	a := AutoResizeGrowthPolicy()
+	assert.Equal(t, 10, a.Grow(6, 8))
+	assert.Equal(t, 10, a.Shrink(4, 10))
+	assert.Equal(t, 8, a.Shrink(3, 10))
+	// после расширения очередь не сжимается, а после сжатия - не расширяется
+	assert.Equal(t, 10, a.Shrink(6, 10))
+	assert.Equal(t, 8, a.Grow(3, 8))
+
+	// нулевое значение и коэффициенты, не превышающие 1, не меняют размер
+	var z GeometricGrowth
+	assert.Equal(t, 8, z.Grow(8, 8))
+	assert.Equal(t, 8, z.Shrink(0, 8))
+	h := GeometricGrowth{GrowAt: 1, GrowBy: 0.5, ShrinkAt: 1, ShrinkBy: 1}
+	assert.Equal(t, 8, h.Grow(8, 8))
+	assert.Equal(t, 8, h.Shrink(0, 8))
+
+	f := FixedGrowth(3, 10)
+	assert.Equal(t, 8, f.Grow(7, 8))
+	assert.Equal(t, 10, f.Grow(8, 8))
+	assert.Equal(t, 10, f.Grow(10, 10))
+	assert.Equal(t, 10, f.Shrink(5, 10))
+	assert.Equal(t, 7, f.Shrink(4, 10))
+	assert.Equal(t, 1, FixedGrowth(0, 0).Shrink(0, 2))
+
+}
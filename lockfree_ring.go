@@ -0,0 +1,255 @@
+package container
+
+import (
+	"context"
+	"math/bits"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// cacheLinePad - выравнивание, разносящее счётчики, изменяемые разными ядрами,
+// по разным строкам кэша (иначе запись в один счётчик сбрасывает кэш другого)
+type cacheLinePad [64]byte
+
+// ringCapacity - ёмкость lock-free буфера: capacity, округлённая вверх до степени
+// двойки (не меньше 2), чтобы позицию в буфере можно было вычислять маской
+func ringCapacity(capacity int) uint64 {
+	if capacity <= 2 {
+		return 2
+	}
+	return 1 << bits.Len64(uint64(capacity-1))
+}
+
+// ringWait - ожидание в блокирующих операциях lock-free буферов: сначала уступает
+// процессор, затем засыпает на всё большее время (до миллисекунды); возвращает
+// ctx.Err(), если контекст отменён
+func ringWait(ctx context.Context, attempt int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if attempt < 16 {
+		runtime.Gosched()
+		return nil
+	}
+	time.Sleep(min(time.Microsecond<<min(attempt-16, 10), time.Millisecond))
+	return nil
+}
+
+// region - SPSC
+
+// SPSCRing - lock-free кольцевой буфер (FIFO) для одного писателя и одного читателя:
+// Push-методы можно вызывать только из одной горутины, Pop-методы - только из одной
+// (возможно, другой). Ёмкость округляется вверх до степени двойки. В отличие
+// от RingBuffer, заполненный буфер не перезаписывается: TryPush возвращает false.
+type SPSCRing[T any] struct {
+	_          cacheLinePad
+	head       atomic.Uint64 // Позиция чтения (изменяется только читателем)
+	cachedTail uint64        // Позиция записи, последняя прочитанная читателем
+	_          cacheLinePad
+	tail       atomic.Uint64 // Позиция записи (изменяется только писателем)
+	cachedHead uint64        // Позиция чтения, последняя прочитанная писателем
+	_          cacheLinePad
+	items      []T
+	mask       uint64
+}
+
+func NewSPSCRing[T any](capacity int) *SPSCRing[T] {
+	c := ringCapacity(capacity)
+	return &SPSCRing[T]{
+		items: make([]T, c),
+		mask:  c - 1,
+	}
+}
+
+// TryPush - добавляет элемент; возвращает false, если буфер заполнен
+func (r *SPSCRing[T]) TryPush(item T) bool {
+	tail := r.tail.Load()
+	if tail-r.cachedHead > r.mask {
+		r.cachedHead = r.head.Load()
+		if tail-r.cachedHead > r.mask {
+			return false
+		}
+	}
+	r.items[tail&r.mask] = item
+	r.tail.Store(tail + 1)
+	return true
+}
+
+// TryPop - извлекает самый старый элемент; возвращает false, если буфер пуст
+func (r *SPSCRing[T]) TryPop() (T, bool) {
+	head := r.head.Load()
+	if head == r.cachedTail {
+		r.cachedTail = r.tail.Load()
+		if head == r.cachedTail {
+			var empty T
+			return empty, false
+		}
+	}
+	item := r.items[head&r.mask]
+	var empty T
+	r.items[head&r.mask] = empty
+	r.head.Store(head + 1)
+	return item, true
+}
+
+// PushWait - добавляет элемент, ожидая освобождения места или отмены контекста
+// (возвращает ctx.Err())
+func (r *SPSCRing[T]) PushWait(ctx context.Context, item T) error {
+	for attempt := 0; !r.TryPush(item); attempt++ {
+		if err := ringWait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PopWait - извлекает элемент, ожидая его появления или отмены контекста
+// (возвращает ctx.Err())
+func (r *SPSCRing[T]) PopWait(ctx context.Context) (T, error) {
+	for attempt := 0; ; attempt++ {
+		if item, ok := r.TryPop(); ok {
+			return item, nil
+		}
+		if err := ringWait(ctx, attempt); err != nil {
+			var empty T
+			return empty, err
+		}
+	}
+}
+
+// Len - возвращает количество элементов в буфере (при конкурентном доступе - оценку)
+func (r *SPSCRing[T]) Len() int {
+	head := r.head.Load()
+	return int(min(r.tail.Load()-head, r.mask+1))
+}
+
+// Cap - возвращает ёмкость буфера (степень двойки)
+func (r *SPSCRing[T]) Cap() int {
+	return len(r.items)
+}
+
+// endregion
+// region - MPMC
+
+type mpmcCell[T any] struct {
+	seq  atomic.Uint64 // Номер позиции, для которой ячейка готова к записи (pos) или чтению (pos+1)
+	item T
+}
+
+// MPMCRing - lock-free ограниченная очередь (FIFO) для любого количества писателей
+// и читателей (алгоритм Д. Вьюкова: каждая ячейка хранит номер последовательности,
+// по которому писатели и читатели узнают, свободна ли она). Ёмкость округляется вверх
+// до степени двойки. Заполненный буфер не перезаписывается: TryPush возвращает false.
+type MPMCRing[T any] struct {
+	_     cacheLinePad
+	tail  atomic.Uint64 // Следующая позиция записи
+	_     cacheLinePad
+	head  atomic.Uint64 // Следующая позиция чтения
+	_     cacheLinePad
+	cells []mpmcCell[T]
+	mask  uint64
+}
+
+func NewMPMCRing[T any](capacity int) *MPMCRing[T] {
+	c := ringCapacity(capacity)
+	r := &MPMCRing[T]{
+		cells: make([]mpmcCell[T], c),
+		mask:  c - 1,
+	}
+	for i := range r.cells {
+		r.cells[i].seq.Store(uint64(i))
+	}
+	return r
+}
+
+// TryPush - добавляет элемент; возвращает false, если буфер заполнен
+func (r *MPMCRing[T]) TryPush(item T) bool {
+	pos := r.tail.Load()
+	for {
+		cell := &r.cells[pos&r.mask]
+		switch dif := int64(cell.seq.Load() - pos); {
+		case dif == 0:
+			// ячейка свободна - занимаем позицию
+			if r.tail.CompareAndSwap(pos, pos+1) {
+				cell.item = item
+				cell.seq.Store(pos + 1)
+				return true
+			}
+			pos = r.tail.Load()
+		case dif < 0:
+			// ячейку ещё не освободил читатель предыдущего круга
+			return false
+		default:
+			// позицию уже занял другой писатель
+			pos = r.tail.Load()
+		}
+	}
+}
+
+// TryPop - извлекает самый старый элемент; возвращает false, если буфер пуст
+func (r *MPMCRing[T]) TryPop() (T, bool) {
+	pos := r.head.Load()
+	for {
+		cell := &r.cells[pos&r.mask]
+		switch dif := int64(cell.seq.Load() - (pos + 1)); {
+		case dif == 0:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				item := cell.item
+				var empty T
+				cell.item = empty
+				cell.seq.Store(pos + r.mask + 1)
+				return item, true
+			}
+			pos = r.head.Load()
+		case dif < 0:
+			// ячейка ещё не записана
+			var empty T
+			return empty, false
+		default:
+			pos = r.head.Load()
+		}
+	}
+}
+
+// PushWait - добавляет элемент, ожидая освобождения места или отмены контекста
+// (возвращает ctx.Err())
+func (r *MPMCRing[T]) PushWait(ctx context.Context, item T) error {
+	for attempt := 0; !r.TryPush(item); attempt++ {
+		if err := ringWait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PopWait - извлекает элемент, ожидая его появления или отмены контекста
+// (возвращает ctx.Err())
+func (r *MPMCRing[T]) PopWait(ctx context.Context) (T, error) {
+	for attempt := 0; ; attempt++ {
+		if item, ok := r.TryPop(); ok {
+			return item, nil
+		}
+		if err := ringWait(ctx, attempt); err != nil {
+			var empty T
+			return empty, err
+		}
+	}
+}
+
+// Len - возвращает количество элементов в буфере (при конкурентном доступе - оценку)
+func (r *MPMCRing[T]) Len() int {
+	head := r.head.Load()
+	tail := r.tail.Load()
+	if tail < head {
+		return 0
+	}
+	return int(min(tail-head, r.mask+1))
+}
+
+// Cap - возвращает ёмкость буфера (степень двойки)
+func (r *MPMCRing[T]) Cap() int {
+	return len(r.cells)
+}
+
+// endregion
@@ -0,0 +1,230 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+type lockFreeRing interface {
+	TryPush(item int) bool
+	TryPop() (int, bool)
+	PushWait(ctx context.Context, item int) error
+	PopWait(ctx context.Context) (int, error)
+	Len() int
+	Cap() int
+}
+
+func TestLockFreeRingBasic(t *testing.T) {
+
+	assert.Equal(t, uint64(2), ringCapacity(0))
+	assert.Equal(t, uint64(2), ringCapacity(2))
+	assert.Equal(t, uint64(4), ringCapacity(3))
+	assert.Equal(t, uint64(64), ringCapacity(64))
+	assert.Equal(t, uint64(128), ringCapacity(65))
+
+	for name, r := range map[string]lockFreeRing{"spsc": NewSPSCRing[int](3), "mpmc": NewMPMCRing[int](3)} {
+		t.Run(name, func(t *testing.T) {
+
+			assert.Equal(t, 4, r.Cap())
+			_, ok := r.TryPop()
+			assert.False(t, ok)
+
+			// несколько кругов по буферу: порядок FIFO, без перезаписи
+			for round := 0; round < 3; round++ {
+				for i := 0; i < 4; i++ {
+					assert.True(t, r.TryPush(round*10+i))
+				}
+				assert.False(t, r.TryPush(-1))
+				assert.Equal(t, 4, r.Len())
+				for i := 0; i < 4; i++ {
+					v, ok := r.TryPop()
+					assert.True(t, ok)
+					assert.Equal(t, round*10+i, v)
+				}
+				assert.Equal(t, 0, r.Len())
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err := r.PopWait(ctx)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		})
+	}
+
+}
+
+func TestSPSCRingOrder(t *testing.T) {
+
+	const n = 100_000
+	r := NewSPSCRing[int](16)
+
+	go func() {
+		for i := 0; i < n; i++ {
+			assert.NoError(t, r.PushWait(context.Background(), i))
+		}
+	}()
+	for i := 0; i < n; i++ {
+		v, err := r.PopWait(context.Background())
+		assert.NoError(t, err)
+		if !assert.Equal(t, i, v) {
+			return
+		}
+	}
+
+}
+
+func TestMPMCRingConcurrent(t *testing.T) {
+
+	const (
+		producers = 8
+		consumers = 8
+		perWorker = 10_000
+	)
+
+	// каждый элемент извлекается ровно один раз, элементы одного писателя - по порядку
+	r := NewMPMCRing[int](64)
+	popped := make([][]int, consumers)
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				assert.NoError(t, r.PushWait(context.Background(), p*perWorker+i))
+			}
+		}()
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				v, err := r.PopWait(context.Background())
+				assert.NoError(t, err)
+				popped[c] = append(popped[c], v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[int]bool, producers*perWorker)
+	for _, values := range popped {
+		last := make(map[int]int)
+		for _, v := range values {
+			assert.False(t, seen[v], "item %d popped twice", v)
+			seen[v] = true
+			p := v / perWorker
+			if prev, ok := last[p]; ok {
+				assert.Less(t, prev, v, "producer %d items out of order", p)
+			}
+			last[p] = v
+		}
+	}
+	assert.Equal(t, producers*perWorker, len(seen))
+	assert.Equal(t, 0, r.Len())
+
+}
+
+// BenchmarkRings - пара push/pop в одной горутине и передача элементов от писателя
+// к читателю; чтобы RingBuffer не перезаписывал непрочитанные элементы, писатель
+// ждёт, пока в нём освободится место (как при TryPush в заполненный lock-free буфер)
+func BenchmarkRings(b *testing.B) {
+
+	type ring struct {
+		push func(int) bool
+		pop  func() (int, bool)
+	}
+	implementations := []struct {
+		name    string
+		newRing func() ring
+	}{
+		{"RingBuffer", func() ring {
+			r := NewRingBuffer[int](1024, RingBufferWithFIFO())
+			return ring{
+				push: func(v int) bool {
+					// писатель один, поэтому место не может закончиться между проверкой и записью
+					return r.Len() < r.Cap() && r.TryPush(v) == nil
+				},
+				pop: func() (int, bool) {
+					v, err := r.Pop()
+					return v, err == nil
+				},
+			}
+		}},
+		{"SPSCRing", func() ring {
+			r := NewSPSCRing[int](1024)
+			return ring{push: r.TryPush, pop: r.TryPop}
+		}},
+		{"MPMCRing", func() ring {
+			r := NewMPMCRing[int](1024)
+			return ring{push: r.TryPush, pop: r.TryPop}
+		}},
+	}
+
+	for _, impl := range implementations {
+		b.Run(fmt.Sprintf("pushpop/%s", impl.name), func(b *testing.B) {
+			r := impl.newRing()
+			for i := 0; i < b.N; i++ {
+				r.push(i)
+				r.pop()
+			}
+		})
+	}
+	for _, impl := range implementations {
+		b.Run(fmt.Sprintf("handoff/%s", impl.name), func(b *testing.B) {
+			r := impl.newRing()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < b.N; {
+					if _, ok := r.pop(); ok {
+						i++
+						continue
+					}
+					runtime.Gosched()
+				}
+			}()
+			for i := 0; i < b.N; i++ {
+				for !r.push(i) {
+					runtime.Gosched()
+				}
+			}
+			<-done
+		})
+	}
+
+}
+
+// BenchmarkRingsContention - конкурентные push/pop из нескольких горутин
+// (SPSCRing не поддерживает нескольких писателей и не участвует)
+func BenchmarkRingsContention(b *testing.B) {
+	for _, procs := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("RingBuffer/procs=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			r := NewRingBuffer[int](1024, RingBufferWithFIFO())
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					r.Push(i)
+					_, _ = r.Pop()
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("MPMCRing/procs=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			r := NewMPMCRing[int](1024)
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					r.TryPush(i)
+					r.TryPop()
+				}
+			})
+		})
+	}
+}
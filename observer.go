@@ -0,0 +1,125 @@
+package container
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// DequeEvent - событие очереди, передаваемое Observer
+type DequeEvent struct {
+	Side     Side // Сторона очереди, к которой относится событие (для изменения размера - SideHead)
+	Size     int  // Количество элементов в очереди после события
+	Capacity int  // Максимальный размер очереди после события (-1 для безлимитной очереди)
+}
+
+// Observer - получатель событий очереди (см. DequeWithObserver). Deque вызывает его
+// методы под своей блокировкой, поэтому они должны быть быстрыми и не должны
+// обращаться к самой очереди; ConcurrentDeque может вызывать их конкурентно.
+// Для реализации части методов можно встроить NopObserver.
+type Observer interface {
+	OnPush(e DequeEvent)                    // Элемент добавлен
+	OnPop(e DequeEvent)                     // Элемент извлечён (в т.ч. при Flush и DrainAll)
+	OnReject(e DequeEvent)                  // Добавление отклонено: очередь заполнена (ErrDequeueFull)
+	OnEvict(e DequeEvent)                   // Элемент вытеснен
+	OnResize(e DequeEvent, oldCapacity int) // Изменился максимальный размер очереди
+}
+
+// NopObserver - Observer, игнорирующий все события
+type NopObserver struct{}
+
+func (NopObserver) OnPush(DequeEvent)        {}
+func (NopObserver) OnPop(DequeEvent)         {}
+func (NopObserver) OnReject(DequeEvent)      {}
+func (NopObserver) OnEvict(DequeEvent)       {}
+func (NopObserver) OnResize(DequeEvent, int) {}
+
+// DequeStats - снимок счётчиков DequeCounter; пропускная способность вычисляется по
+// приращению Pushes/Pops между снимками (или с момента Started)
+type DequeStats struct {
+	Length    int       `json:"length"`    // Текущее количество элементов
+	HighWater int       `json:"highWater"` // Максимальное количество элементов
+	Capacity  int       `json:"capacity"`  // Текущий максимальный размер очереди
+	Pushes    int64     `json:"pushes"`    // Добавлено элементов
+	Pops      int64     `json:"pops"`      // Извлечено элементов
+	Rejects   int64     `json:"rejects"`   // Отклонено добавлений
+	Evictions int64     `json:"evictions"` // Вытеснено элементов
+	Resizes   int64     `json:"resizes"`   // Изменений максимального размера
+	Started   time.Time `json:"started"`   // Время создания счётчика
+}
+
+// DequeCounter - Observer, подсчитывающий события очереди; безопасен для конкурентного
+// использования. Один счётчик предназначен для одной очереди.
+type DequeCounter struct {
+	length    atomic.Int64
+	highWater atomic.Int64
+	capacity  atomic.Int64
+	pushes    atomic.Int64
+	pops      atomic.Int64
+	rejects   atomic.Int64
+	evictions atomic.Int64
+	resizes   atomic.Int64
+	started   time.Time
+}
+
+func NewDequeCounter() *DequeCounter {
+	return &DequeCounter{
+		started: time.Now(),
+	}
+}
+
+func (c *DequeCounter) OnPush(e DequeEvent) {
+	c.pushes.Add(1)
+	c.update(e)
+}
+func (c *DequeCounter) OnPop(e DequeEvent) {
+	c.pops.Add(1)
+	c.update(e)
+}
+func (c *DequeCounter) OnReject(e DequeEvent) {
+	c.rejects.Add(1)
+	c.update(e)
+}
+func (c *DequeCounter) OnEvict(e DequeEvent) {
+	c.evictions.Add(1)
+	c.update(e)
+}
+func (c *DequeCounter) OnResize(e DequeEvent, _ int) {
+	c.resizes.Add(1)
+	c.update(e)
+}
+
+// Stats - возвращает снимок счётчиков
+func (c *DequeCounter) Stats() DequeStats {
+	return DequeStats{
+		Length:    int(c.length.Load()),
+		HighWater: int(c.highWater.Load()),
+		Capacity:  int(c.capacity.Load()),
+		Pushes:    c.pushes.Load(),
+		Pops:      c.pops.Load(),
+		Rejects:   c.rejects.Load(),
+		Evictions: c.evictions.Load(),
+		Resizes:   c.resizes.Load(),
+		Started:   c.started,
+	}
+}
+
+// Var - адаптер для expvar: публикует Stats() в виде JSON-объекта, например
+// expvar.Publish("jobs", counter.Var())
+func (c *DequeCounter) Var() expvar.Var {
+	return expvar.Func(func() any {
+		return c.Stats()
+	})
+}
+
+func (c *DequeCounter) update(e DequeEvent) {
+	size := int64(e.Size)
+	c.length.Store(size)
+	c.capacity.Store(int64(e.Capacity))
+	for {
+		hw := c.highWater.Load()
+		if size <= hw || c.highWater.CompareAndSwap(hw, size) {
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	NopObserver
+	events []string
+}
+
+func (o *recordingObserver) OnReject(e DequeEvent) {
+	o.events = append(o.events, "reject "+e.Side.String())
+}
+func (o *recordingObserver) OnResize(e DequeEvent, oldCapacity int) {
+	o.events = append(o.events, fmt.Sprintf("resize %d -> %d", oldCapacity, e.Capacity))
+}
+
+func TestDequeCounter(t *testing.T) {
+
+	c := NewDequeCounter()
+	d := NewDeque[int](
+		DequeWithSizeLimit(3),
+		DequeWithPreemption(),
+		DequeWithObserver(c),
+	)
+
+	assert.NoError(t, d.PushTailAll(1, 2, 3, 4)) // 1 вытесняется
+	_, err := d.PopHead()
+	assert.NoError(t, err)
+	assert.NoError(t, d.InsertAt(1, 5))
+	_, err = d.RemoveAt(0)
+	assert.NoError(t, err)
+
+	stats := c.Stats()
+	assert.Equal(t, DequeStats{
+		Length:    2,
+		HighWater: 3,
+		Capacity:  3,
+		Pushes:    5,
+		Pops:      2,
+		Evictions: 1,
+		Started:   stats.Started,
+	}, stats)
+
+	assert.Equal(t, []int{5, 4}, d.DrainAll())
+	assert.NoError(t, d.PushHeadAll(1, 2, 3))
+	assert.NoError(t, d.Expand())
+	assert.NoError(t, d.Shrink())
+	stats = c.Stats()
+	assert.Equal(t, 3, stats.Length)
+	assert.Equal(t, 3, stats.Capacity)
+	assert.Equal(t, int64(4), stats.Pops)
+	assert.Equal(t, int64(2), stats.Resizes)
+
+	// expvar публикует снимок счётчиков в JSON
+	var published DequeStats
+	assert.NoError(t, json.Unmarshal([]byte(c.Var().String()), &published))
+	assert.Equal(t, stats.Pushes, published.Pushes)
+	assert.Equal(t, stats.HighWater, published.HighWater)
+
+}
+func TestDequeObserverEvents(t *testing.T) {
+
+	o := &recordingObserver{}
+	d := NewDeque[int](
+		DequeWithSizeLimit(2),
+		DequeWithObserver(o),
+	)
+	assert.NoError(t, d.PushHeadAll(1, 2))
+	assert.ErrorIs(t, d.PushHead(3), ErrDequeueFull)
+	assert.ErrorIs(t, d.PushTailAll(3, 4), ErrDequeueFull)
+	assert.ErrorIs(t, d.InsertAt(0, 3), ErrDequeueFull)
+	// ожидающее добавление - не отказ
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.PushTailWait(ctx, 3), context.DeadlineExceeded)
+	assert.NoError(t, d.Expand())
+
+	assert.Equal(t, []string{
+		"reject head",
+		"reject tail",
+		"reject head",
+		"resize 2 -> 3",
+	}, o.events)
+
+}
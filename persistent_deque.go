@@ -0,0 +1,623 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// region - codec
+
+// Codec - преобразование элементов PersistentDeque в байты для записи в журнал и обратно
+type Codec[T any] interface {
+	Marshal(item T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+type jsonCodec[T any] struct{}
+
+// JSONCodec - кодек на основе encoding/json (используется по умолчанию)
+func JSONCodec[T any]() Codec[T] {
+	return jsonCodec[T]{}
+}
+func (jsonCodec[T]) Marshal(item T) ([]byte, error) {
+	return json.Marshal(item)
+}
+func (jsonCodec[T]) Unmarshal(data []byte) (item T, err error) {
+	err = json.Unmarshal(data, &item)
+	return
+}
+
+type gobCodec[T any] struct{}
+
+// GobCodec - кодек на основе encoding/gob
+func GobCodec[T any]() Codec[T] {
+	return gobCodec[T]{}
+}
+func (gobCodec[T]) Marshal(item T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(item); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+func (gobCodec[T]) Unmarshal(data []byte) (item T, err error) {
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&item)
+	return
+}
+
+// endregion
+// region - options
+
+type persistentDequeConfig struct {
+	codec        any
+	dequeOptions []DequeOption
+	segmentSize  int64
+	maxSegments  int
+	noSync       bool
+}
+type PersistentDequeOption func(*persistentDequeConfig)
+
+// PersistentDequeWithCodec - кодек элементов очереди (по умолчанию - JSONCodec)
+func PersistentDequeWithCodec[T any](codec Codec[T]) PersistentDequeOption {
+	return func(config *persistentDequeConfig) {
+		config.codec = codec
+	}
+}
+
+// PersistentDequeWithDequeOptions - опции очереди (ограничение размера, вытеснение и т.п.)
+func PersistentDequeWithDequeOptions(opts ...DequeOption) PersistentDequeOption {
+	return func(config *persistentDequeConfig) {
+		config.dequeOptions = append(config.dequeOptions, opts...)
+	}
+}
+
+// PersistentDequeWithSegmentSize - размер сегмента журнала в байтах, при превышении
+// которого запись продолжается в новый сегмент (по умолчанию - 4 МиБ)
+func PersistentDequeWithSegmentSize(size int64) PersistentDequeOption {
+	return func(config *persistentDequeConfig) {
+		config.segmentSize = size
+	}
+}
+
+// PersistentDequeWithCompactionThreshold - количество сегментов журнала, при превышении
+// которого журнал сжимается до текущего содержимого очереди (по умолчанию - 4)
+func PersistentDequeWithCompactionThreshold(segments int) PersistentDequeOption {
+	return func(config *persistentDequeConfig) {
+		config.maxSegments = segments
+	}
+}
+
+// PersistentDequeWithoutSync - не вызывать fsync после каждой записи в журнал: быстрее,
+// но при сбое ОС (в отличие от аварийного завершения процесса) последние изменения
+// могут быть потеряны
+func PersistentDequeWithoutSync() PersistentDequeOption {
+	return func(config *persistentDequeConfig) {
+		config.noSync = true
+	}
+}
+
+// codec - возвращает заданный опцией PersistentDequeWithCodec кодек, проверяя,
+// что он соответствует типу элементов очереди
+func codec[T any](pc *persistentDequeConfig) Codec[T] {
+	if pc.codec == nil {
+		return JSONCodec[T]()
+	}
+	c, ok := pc.codec.(Codec[T])
+	if !ok {
+		panic(fmt.Sprintf("container: codec %T does not match element type", pc.codec))
+	}
+	return c
+}
+
+// endregion
+// region - errors
+
+var (
+	ErrCorruptedLog = errors.New("corrupted log")
+)
+
+// endregion
+
+const (
+	segmentExt = ".wal"
+	tmpExt     = ".tmp"
+)
+
+// операции журнала
+const (
+	logPushHead byte = iota + 1
+	logPushTail
+	logPopHead
+	logPopTail
+	logReset // очистка очереди (в начале сжатого сегмента и при Flush)
+)
+
+// PersistentDeque - двусторонняя очередь, сохраняющая своё содержимое на диске: каждое
+// добавление и извлечение элемента записывается в журнал (набор сегментов в каталоге
+// очереди) до того, как операция завершится, а при открытии очередь восстанавливается
+// из журнала. Журнал периодически сжимается до текущего содержимого очереди.
+// Принимает те же опции, что и Deque (через PersistentDequeWithDequeOptions).
+type PersistentDeque[T any] struct {
+	deque       *Deque[T]
+	codec       Codec[T]
+	dir         string
+	segments    []uint64    // Номера сегментов журнала по возрастанию; последний - текущий
+	file        segmentFile // Текущий сегмент
+	offset      int64       // Размер текущего сегмента
+	segmentSize int64
+	maxSegments int
+	sync        bool
+	preemption  bool
+	autoResize  bool
+	maxSize     int
+	replaying   bool // Идёт восстановление из журнала (обработчик вытеснения не вызывается)
+	closed      bool
+	failed      error // Журнал не удалось вернуть в согласованное состояние; дальнейшая запись невозможна
+	mutex       sync.Mutex
+}
+
+// segmentFile - открытый для дозаписи сегмент журнала (*os.File; в тестах подменяется
+// для имитации сбоев диска)
+type segmentFile interface {
+	io.WriteSeeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// OpenPersistentDeque - открывает (создаёт) очередь в каталоге dir и восстанавливает
+// её содержимое из журнала; неполная запись в конце журнала (после аварийного
+// завершения процесса) отбрасывается
+func OpenPersistentDeque[T any](dir string, opts ...PersistentDequeOption) (*PersistentDeque[T], error) {
+
+	pc := &persistentDequeConfig{
+		segmentSize: 4 << 20,
+		maxSegments: 4,
+	}
+	for _, opt := range opts {
+		opt(pc)
+	}
+
+	s := &PersistentDeque[T]{
+		codec:       codec[T](pc),
+		dir:         dir,
+		segmentSize: max(1, pc.segmentSize),
+		maxSegments: max(1, pc.maxSegments),
+		sync:        !pc.noSync,
+	}
+
+	qc := newQueueConfig(pc.dequeOptions...)
+	s.preemption = qc.preemption
+	s.autoResize = qc.autoResize
+	s.maxSize = qc.maxSize
+	onEvict := evictionHandler[T](qc)
+	s.deque = NewDeque[T](append(slices.Clone(pc.dequeOptions), DequeWithEvictionHandler(func(item T, side Side) {
+		// при восстановлении вытеснения повторяются, но обработчик их уже видел
+		if onEvict != nil && !s.replaying {
+			onEvict(item, side)
+		}
+	}))...)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := s.recover(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+
+}
+
+// Close - закрывает очередь и файл журнала; содержимое очереди остаётся на диске
+func (s *PersistentDeque[T]) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.deque.Close()
+	return s.file.Close()
+}
+
+func (s *PersistentDeque[T]) PushHead(item T) error {
+	return s.push(item, SideHead)
+}
+func (s *PersistentDeque[T]) PushTail(item T) error {
+	return s.push(item, SideTail)
+}
+func (s *PersistentDeque[T]) PopHead() (T, error) {
+	return s.pop(SideHead)
+}
+func (s *PersistentDeque[T]) PopTail() (T, error) {
+	return s.pop(SideTail)
+}
+func (s *PersistentDeque[T]) PeekHead() (T, error) {
+	return s.deque.PeekHead()
+}
+func (s *PersistentDeque[T]) PeekTail() (T, error) {
+	return s.deque.PeekTail()
+}
+
+// Flush - удаляет все элементы очереди
+func (s *PersistentDeque[T]) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if err := s.write(logReset, nil); err != nil {
+		return err
+	}
+	s.deque.Flush()
+	s.roll()
+	return nil
+}
+
+// Compact - сжимает журнал до текущего содержимого очереди
+func (s *PersistentDeque[T]) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	return s.compact()
+}
+
+func (s *PersistentDeque[T]) Values() []T {
+	return s.deque.Values()
+}
+
+// Size - возвращает количество элементов в очереди
+func (s *PersistentDeque[T]) Size() int {
+	return s.deque.Size()
+}
+
+// Capacity - возвращает максимальный размер очереди; -1 для неограниченной очереди
+func (s *PersistentDeque[T]) Capacity() int {
+	return s.deque.Capacity()
+}
+
+// push - изменения очереди выполняются только под s.mutex, поэтому проверка
+// заполненности до записи в журнал остаётся верной и после неё
+func (s *PersistentDeque[T]) push(item T, side Side) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if c := s.deque.Capacity(); c > 0 && s.deque.Size() >= c && !s.preemption && (!s.autoResize || c >= s.maxSize) {
+		// пока очередь может расширяться, заполненность проверяет она сама; отклонённое
+		// ей добавление так же отклоняется при восстановлении из журнала
+		return ErrDequeueFull
+	}
+	data, err := s.codec.Marshal(item)
+	if err != nil {
+		return err
+	}
+	op := logPushTail
+	if side == SideHead {
+		op = logPushHead
+	}
+	if err = s.write(op, data); err != nil {
+		return err
+	}
+	defer s.roll()
+	if side == SideHead {
+		return s.deque.PushHead(item)
+	}
+	return s.deque.PushTail(item)
+}
+func (s *PersistentDeque[T]) pop(side Side) (T, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var empty T
+	if s.closed {
+		return empty, ErrClosed
+	}
+	if s.deque.Size() == 0 {
+		return empty, ErrDequeueEmpty
+	}
+	op := logPopTail
+	if side == SideHead {
+		op = logPopHead
+	}
+	if err := s.write(op, nil); err != nil {
+		return empty, err
+	}
+	defer s.roll()
+	if side == SideHead {
+		return s.deque.PopHead()
+	}
+	return s.deque.PopTail()
+}
+
+// region - log
+
+// Запись журнала: длина (4 байта) и CRC32 (4 байта) данных, затем данные - код
+// операции и закодированный элемент
+
+const recordHeaderSize = 8
+
+func encodeRecord(op byte, data []byte) []byte {
+	record := make([]byte, recordHeaderSize+1+len(data))
+	record[recordHeaderSize] = op
+	copy(record[recordHeaderSize+1:], data)
+	binary.LittleEndian.PutUint32(record[0:], uint32(1+len(data)))
+	binary.LittleEndian.PutUint32(record[4:], crc32.ChecksumIEEE(record[recordHeaderSize:]))
+	return record
+}
+
+// readRecord - читает очередную запись; io.EOF - конец сегмента,
+// io.ErrUnexpectedEOF - неполная или повреждённая запись
+func readRecord(r io.Reader) (op byte, data []byte, err error) {
+	var header [recordHeaderSize]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	size := binary.LittleEndian.Uint32(header[0:])
+	if size == 0 || size > 1<<30 {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	body := make([]byte, size)
+	if _, err = io.ReadFull(r, body); err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(header[4:]) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	return body[0], body[1:], nil
+}
+
+// write - дописывает запись в текущий сегмент; если запись или fsync не удались,
+// запись отрезается (см. rollback)
+func (s *PersistentDeque[T]) write(op byte, data []byte) error {
+	if s.failed != nil {
+		return s.failed
+	}
+	record := encodeRecord(op, data)
+	_, err := s.file.Write(record)
+	if err == nil && s.sync {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		s.rollback()
+		return err
+	}
+	s.offset += int64(len(record))
+	return nil
+}
+
+// rollback - отрезает от сегмента запись, о неудаче которой сообщено вызывающему:
+// иначе при восстановлении она была бы применена (а повторённое извлечение удалило
+// бы лишний элемент). Если отрезать запись не удалось, журнал больше не изменяется -
+// запись в него завершается ошибкой ErrCorruptedLog.
+func (s *PersistentDeque[T]) rollback() {
+	err := s.file.Truncate(s.offset)
+	if err == nil {
+		_, err = s.file.Seek(s.offset, io.SeekStart)
+	}
+	// отдельный fsync не нужен: новый размер файла сохранит fsync следующей записи
+	if err != nil {
+		s.failed = fmt.Errorf("%w: rollback failed: %w", ErrCorruptedLog, err)
+	}
+}
+
+// roll - при заполнении текущего сегмента начинает новый, а при превышении порога
+// сжимает журнал; вызывается после применения записанной операции к очереди, так как
+// сжатие сохраняет её текущее содержимое. Операция уже в журнале, поэтому ошибка
+// не возвращается: запись продолжается в текущий сегмент до следующей попытки
+func (s *PersistentDeque[T]) roll() {
+	if s.offset >= s.segmentSize {
+		_ = s.rollSegment()
+	}
+}
+func (s *PersistentDeque[T]) rollSegment() error {
+	if len(s.segments) >= s.maxSegments {
+		return s.compact()
+	}
+	file, err := s.create(s.segments[len(s.segments)-1]+1, nil)
+	if err != nil {
+		return err
+	}
+	_ = s.file.Close()
+	s.file, s.offset = file, 0
+	s.segments = append(s.segments, s.segments[len(s.segments)-1]+1)
+	return nil
+}
+
+// compact - записывает текущее содержимое очереди в новый сегмент, начинающийся с
+// очистки очереди, и удаляет предыдущие сегменты; если процесс завершится до их
+// удаления, восстановление всё равно даст верный результат
+func (s *PersistentDeque[T]) compact() error {
+	var buf bytes.Buffer
+	buf.Write(encodeRecord(logReset, nil))
+	for item := range s.deque.All() {
+		data, err := s.codec.Marshal(item)
+		if err != nil {
+			return err
+		}
+		buf.Write(encodeRecord(logPushTail, data))
+	}
+	id := s.segments[len(s.segments)-1] + 1
+	file, err := s.create(id, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_ = s.file.Close()
+	for _, old := range s.segments {
+		_ = os.Remove(s.segmentPath(old))
+	}
+	s.file, s.offset = file, int64(buf.Len())
+	s.segments = []uint64{id}
+	return nil
+}
+
+// create - атомарно создаёт сегмент с заданным содержимым и открывает его для дозаписи
+func (s *PersistentDeque[T]) create(id uint64, content []byte) (*os.File, error) {
+	path := s.segmentPath(id)
+	tmp := path + tmpExt
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return nil, err
+	}
+	if err := syncFile(tmp); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	if err := syncDir(s.dir); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// syncDir - сохраняет на диск изменения каталога (создание и переименование файлов);
+// в Windows каталог нельзя открыть для fsync (а NTFS журналирует метаданные сама),
+// поэтому там шаг пропускается
+func syncDir(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	return syncFile(path)
+}
+func syncFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+func (s *PersistentDeque[T]) segmentPath(id uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// recover - восстанавливает очередь из сегментов журнала и открывает последний
+// из них для дозаписи
+func (s *PersistentDeque[T]) recover() error {
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasSuffix(name, tmpExt):
+			// незавершённое сжатие или создание сегмента
+			_ = os.Remove(filepath.Join(s.dir, name))
+		case strings.HasSuffix(name, segmentExt):
+			id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+			if err != nil {
+				continue
+			}
+			s.segments = append(s.segments, id)
+		}
+	}
+	slices.Sort(s.segments)
+
+	if len(s.segments) == 0 {
+		if s.file, err = s.create(1, nil); err != nil {
+			return err
+		}
+		s.segments = []uint64{1}
+		return nil
+	}
+
+	s.replaying = true
+	defer func() { s.replaying = false }()
+
+	for i, id := range s.segments {
+		last := i == len(s.segments)-1
+		valid, err := s.replay(id)
+		if err != nil && (!last || !errors.Is(err, io.ErrUnexpectedEOF)) {
+			return fmt.Errorf("%w: segment %d: %w", ErrCorruptedLog, id, err)
+		}
+		if last {
+			// отбрасываем неполную запись в конце журнала
+			if err = os.Truncate(s.segmentPath(id), valid); err != nil {
+				return err
+			}
+			s.offset = valid
+		}
+	}
+
+	s.file, err = os.OpenFile(s.segmentPath(s.segments[len(s.segments)-1]), os.O_WRONLY|os.O_APPEND, 0o644)
+	return err
+
+}
+
+// replay - применяет записи сегмента к очереди; возвращает размер его корректной части
+func (s *PersistentDeque[T]) replay(id uint64) (int64, error) {
+	f, err := os.Open(s.segmentPath(id))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var valid int64
+	for {
+		op, data, err := readRecord(r)
+		if err == io.EOF {
+			return valid, nil
+		}
+		if err != nil {
+			return valid, err
+		}
+		if err = s.apply(op, data); err != nil {
+			return valid, err
+		}
+		valid += int64(recordHeaderSize + 1 + len(data))
+	}
+}
+func (s *PersistentDeque[T]) apply(op byte, data []byte) error {
+	switch op {
+	case logPushHead, logPushTail:
+		item, err := s.codec.Unmarshal(data)
+		if err != nil {
+			return err
+		}
+		if op == logPushHead {
+			err = s.deque.PushHead(item)
+		} else {
+			err = s.deque.PushTail(item)
+		}
+		// с уменьшенным ограничением размера часть элементов может не поместиться
+		if err != nil && !errors.Is(err, ErrDequeueFull) {
+			return err
+		}
+	case logPopHead:
+		_, _ = s.deque.PopHead()
+	case logPopTail:
+		_, _ = s.deque.PopTail()
+	case logReset:
+		s.deque.Flush()
+	default:
+		return fmt.Errorf("unknown operation %d", op)
+	}
+	return nil
+}
+
+// endregion
@@ -0,0 +1,293 @@
+package container
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestPersistentDequeReopen(t *testing.T) {
+
+	dir := t.TempDir()
+
+	d, err := OpenPersistentDeque[string](dir)
+	assert.NoError(t, err)
+	assert.NoError(t, d.PushTail("b"))
+	assert.NoError(t, d.PushTail("c"))
+	assert.NoError(t, d.PushHead("a"))
+	assert.NoError(t, d.PushTail("d"))
+	v, err := d.PopTail()
+	assert.NoError(t, err)
+	assert.Equal(t, "d", v)
+	assert.NoError(t, d.Close())
+	assert.ErrorIs(t, d.PushTail("x"), ErrClosed)
+
+	d, err = OpenPersistentDeque[string](dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, d.Values())
+	v, err = d.PopHead()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", v)
+	assert.NoError(t, d.Close())
+
+	d, err = OpenPersistentDeque[string](dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, d.Values())
+	assert.NoError(t, d.Flush())
+	assert.NoError(t, d.Close())
+
+	d, err = OpenPersistentDeque[string](dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, d.Size())
+	_, err = d.PopHead()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+	assert.NoError(t, d.Close())
+
+}
+func TestPersistentDequeOptions(t *testing.T) {
+
+	dir := t.TempDir()
+	var evicted []int
+	open := func() *PersistentDeque[int] {
+		d, err := OpenPersistentDeque[int](dir,
+			PersistentDequeWithCodec(GobCodec[int]()),
+			PersistentDequeWithDequeOptions(
+				DequeWithSizeLimit(3),
+				DequeWithPreemption(),
+				DequeWithEvictionHandler(func(item int, _ Side) { evicted = append(evicted, item) }),
+			),
+		)
+		assert.NoError(t, err)
+		return d
+	}
+
+	d := open()
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, d.PushTail(i))
+	}
+	assert.Equal(t, []int{3, 4, 5}, d.Values())
+	assert.Equal(t, []int{1, 2}, evicted)
+	assert.NoError(t, d.Close())
+
+	// вытеснения при восстановлении не передаются обработчику повторно
+	d = open()
+	assert.Equal(t, []int{3, 4, 5}, d.Values())
+	assert.Equal(t, 3, d.Capacity())
+	assert.Equal(t, []int{1, 2}, evicted)
+	assert.NoError(t, d.Close())
+
+	d, err := OpenPersistentDeque[int](dir,
+		PersistentDequeWithCodec(GobCodec[int]()),
+		PersistentDequeWithDequeOptions(DequeWithSizeLimit(3)),
+	)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, d.PushTail(6), ErrDequeueFull)
+	assert.NoError(t, d.Close())
+
+	assert.Panics(t, func() {
+		_, _ = OpenPersistentDeque[int](dir, PersistentDequeWithCodec(JSONCodec[string]()))
+	})
+
+}
+func TestPersistentDequeCompaction(t *testing.T) {
+
+	dir := t.TempDir()
+	open := func() *PersistentDeque[int] {
+		d, err := OpenPersistentDeque[int](dir,
+			PersistentDequeWithSegmentSize(256),
+			PersistentDequeWithCompactionThreshold(3),
+			PersistentDequeWithoutSync(),
+		)
+		assert.NoError(t, err)
+		return d
+	}
+	segments := func() int {
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		assert.NoError(t, err)
+		return len(files)
+	}
+
+	d := open()
+	var expected []int
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, d.PushTail(i))
+		expected = append(expected, i)
+		if i%3 == 0 {
+			_, err := d.PopHead()
+			assert.NoError(t, err)
+			expected = expected[1:]
+		}
+		assert.LessOrEqual(t, segments(), 3)
+	}
+	assert.NoError(t, d.Close())
+
+	d = open()
+	assert.Equal(t, expected, d.Values())
+	assert.NoError(t, d.Compact())
+	assert.Equal(t, 1, segments())
+	assert.NoError(t, d.Close())
+
+	d = open()
+	assert.Equal(t, expected, d.Values())
+	assert.NoError(t, d.Close())
+
+	// незавершённое сжатие не мешает восстановлению
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000099.wal.tmp"), []byte("garbage"), 0o644))
+	d = open()
+	assert.Equal(t, expected, d.Values())
+	assert.NoError(t, d.Close())
+	assert.Equal(t, 1, segments())
+
+}
+func TestPersistentDequeRecovery(t *testing.T) {
+
+	dir := t.TempDir()
+	d, err := OpenPersistentDeque[int](dir)
+	assert.NoError(t, err)
+	assert.NoError(t, d.PushTail(1))
+	assert.NoError(t, d.PushTail(2))
+	assert.NoError(t, d.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// недописанная запись в конце журнала отбрасывается
+	f, err := os.OpenFile(files[0], os.O_WRONLY|os.O_APPEND, 0o644)
+	assert.NoError(t, err)
+	_, err = f.Write(encodeRecord(logPushTail, []byte("3"))[:9])
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	d, err = OpenPersistentDeque[int](dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, d.Values())
+	assert.NoError(t, d.PushTail(4))
+	assert.NoError(t, d.Close())
+
+	d, err = OpenPersistentDeque[int](dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 4}, d.Values())
+	assert.NoError(t, d.Close())
+
+	// повреждение не последнего сегмента - ошибка
+	data, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	assert.NoError(t, os.WriteFile(files[0], data, 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000002.wal"), nil, 0o644))
+	_, err = OpenPersistentDeque[int](dir)
+	assert.ErrorIs(t, err, ErrCorruptedLog)
+
+}
+
+// faultySegment - сегмент журнала, fsync (и, если задано, Truncate) которого завершается ошибкой
+type faultySegment struct {
+	segmentFile
+	failSync     bool
+	failTruncate bool
+}
+
+var errDiskFailure = errors.New("disk failure")
+
+func (f *faultySegment) Sync() error {
+	if f.failSync {
+		return errDiskFailure
+	}
+	return f.segmentFile.Sync()
+}
+func (f *faultySegment) Truncate(size int64) error {
+	if f.failTruncate {
+		return errDiskFailure
+	}
+	return f.segmentFile.Truncate(size)
+}
+
+func TestPersistentDequeSyncFailure(t *testing.T) {
+
+	dir := t.TempDir()
+	d, err := OpenPersistentDeque[int](dir)
+	assert.NoError(t, err)
+	assert.NoError(t, d.PushTail(1))
+	assert.NoError(t, d.PushTail(2))
+
+	// операции, о неудаче которых сообщено, не попадают в журнал: неудачное и повторное
+	// извлечение не удаляют при восстановлении два элемента
+	faulty := &faultySegment{segmentFile: d.file, failSync: true}
+	d.file = faulty
+	assert.ErrorIs(t, d.PushTail(3), errDiskFailure)
+	_, err = d.PopHead()
+	assert.ErrorIs(t, err, errDiskFailure)
+	faulty.failSync = false
+	v, err := d.PopHead()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.NoError(t, d.Close())
+
+	d, err = OpenPersistentDeque[int](dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, d.Values())
+
+	// если отрезать запись не удалось, журнал больше не изменяется
+	faulty = &faultySegment{segmentFile: d.file, failSync: true, failTruncate: true}
+	d.file = faulty
+	assert.ErrorIs(t, d.PushTail(3), errDiskFailure)
+	faulty.failSync, faulty.failTruncate = false, false
+	assert.ErrorIs(t, d.PushTail(4), ErrCorruptedLog)
+	_, err = d.PopHead()
+	assert.ErrorIs(t, err, ErrCorruptedLog)
+	assert.Equal(t, []int{2}, d.Values())
+	assert.NoError(t, d.Close())
+
+}
+
+// TestPersistentDequeKill - подтверждённые добавления переживают kill -9: дочерний
+// процесс добавляет элементы и сообщает о каждом успешном добавлении, пока не будет убит
+func TestPersistentDequeKill(t *testing.T) {
+
+	if dir := os.Getenv("PERSISTENT_DEQUE_KILL_DIR"); dir != "" {
+		d, err := OpenPersistentDeque[int](dir, PersistentDequeWithSegmentSize(1024))
+		if err != nil {
+			os.Exit(1)
+		}
+		for i := 0; ; i++ {
+			if err = d.PushTail(i); err != nil {
+				os.Exit(1)
+			}
+			fmt.Println(i)
+		}
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPersistentDequeKill$")
+	cmd.Env = append(os.Environ(), "PERSISTENT_DEQUE_KILL_DIR="+dir)
+	stdout, err := cmd.StdoutPipe()
+	assert.NoError(t, err)
+	assert.NoError(t, cmd.Start())
+
+	acknowledged := -1
+	scanner := bufio.NewScanner(stdout)
+	for acknowledged < 200 && scanner.Scan() {
+		acknowledged, err = strconv.Atoi(scanner.Text())
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, cmd.Process.Kill())
+	_ = cmd.Wait()
+	assert.Equal(t, 200, acknowledged)
+
+	d, err := OpenPersistentDeque[int](dir)
+	assert.NoError(t, err)
+	values := d.Values()
+	assert.GreaterOrEqual(t, len(values), acknowledged+1)
+	for i, v := range values {
+		assert.Equal(t, i, v)
+	}
+	assert.NoError(t, d.Close())
+
+}
@@ -0,0 +1,293 @@
+package container
+
+import (
+	"math/bits"
+	"slices"
+	"sync"
+)
+
+// PriorityDeque - двусторонняя очередь с приоритетом (min-max heap): позволяет за O(log n)
+// извлекать как минимальный, так и максимальный (в смысле функции сравнения) элемент.
+// Приоритет элемента растёт вместе с его значением: минимальный элемент - наименее
+// приоритетный. Из опций Deque учитываются ограничение размера, вытеснение, обработчик
+// вытеснения и Observer. При вытеснении из заполненной очереди удаляется наименее
+// приоритетный элемент (с учётом добавляемого). Обработчику вытеснения и Observer
+// минимальный конец очереди передаётся как SideHead, максимальный - как SideTail;
+// добавление элемента Observer получает как SideTail.
+type PriorityDeque[T any] struct {
+	items      []T              // Элементы очереди в виде min-max heap
+	cmp        func(a, b T) int // Функция сравнения элементов (как в slices.SortFunc)
+	capacity   int              // Максимальный размер очереди (-1 для безлимитной очереди)
+	preemption bool             // Вытеснять наименее приоритетный элемент при добавлении в заполненную очередь
+	onEvict    func(T, Side)    // Обработчик вытесненных элементов
+	observer   Observer         // Получатель событий очереди
+	mutex      sync.RWMutex
+}
+
+func NewPriorityDeque[T any](cmp func(a, b T) int, opts ...DequeOption) *PriorityDeque[T] {
+
+	qc := newQueueConfig(opts...)
+
+	initialCapacity := 8
+	if qc.sizeLimit > 0 {
+		initialCapacity = qc.sizeLimit
+	}
+
+	return &PriorityDeque[T]{
+		items:      make([]T, 0, initialCapacity),
+		cmp:        cmp,
+		capacity:   qc.sizeLimit,
+		preemption: qc.preemption,
+		onEvict:    evictionHandler[T](qc),
+		observer:   qc.observer,
+		mutex:      sync.RWMutex{},
+	}
+
+}
+
+func (s *PriorityDeque[T]) Push(item T) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.push(item)
+}
+func (s *PriorityDeque[T]) PushAll(items ...T) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, item := range items {
+		if err = s.push(item); err != nil {
+			break
+		}
+	}
+	return err
+}
+
+// PopMin - извлекает минимальный (наименее приоритетный) элемент
+func (s *PriorityDeque[T]) PopMin() (T, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.items) == 0 {
+		var empty T
+		return empty, ErrDequeueEmpty
+	}
+	item := s.removeAt(0)
+	s.observe(Observer.OnPop, SideHead)
+	return item, nil
+}
+
+// PopMax - извлекает максимальный (наиболее приоритетный) элемент
+func (s *PriorityDeque[T]) PopMax() (T, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.items) == 0 {
+		var empty T
+		return empty, ErrDequeueEmpty
+	}
+	item := s.removeAt(s.maxIndex())
+	s.observe(Observer.OnPop, SideTail)
+	return item, nil
+}
+
+// PeekMin - возвращает минимальный (наименее приоритетный) элемент, не извлекая его
+func (s *PriorityDeque[T]) PeekMin() (T, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if len(s.items) == 0 {
+		var empty T
+		return empty, ErrDequeueEmpty
+	}
+	return s.items[0], nil
+}
+
+// PeekMax - возвращает максимальный (наиболее приоритетный) элемент, не извлекая его
+func (s *PriorityDeque[T]) PeekMax() (T, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if len(s.items) == 0 {
+		var empty T
+		return empty, ErrDequeueEmpty
+	}
+	return s.items[s.maxIndex()], nil
+}
+
+func (s *PriorityDeque[T]) Flush() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.observer != nil {
+		for n := len(s.items) - 1; n >= 0; n-- {
+			s.observer.OnPop(DequeEvent{Side: SideHead, Size: n, Capacity: s.capacity})
+		}
+	}
+	s.items = make([]T, 0, cap(s.items))
+}
+
+// Values - возвращает элементы очереди, упорядоченные от минимального к максимальному
+func (s *PriorityDeque[T]) Values() []T {
+	s.mutex.RLock()
+	v := slices.Clone(s.items)
+	s.mutex.RUnlock()
+	slices.SortFunc(v, s.cmp)
+	return v
+}
+
+// Size - возвращает количество элементов в очереди
+func (s *PriorityDeque[T]) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.items)
+}
+
+// Capacity - возвращает максимальный размер очереди; -1 для неограниченной очереди
+func (s *PriorityDeque[T]) Capacity() int {
+	return s.capacity
+}
+
+func (s *PriorityDeque[T]) push(item T) error {
+	if s.capacity > 0 && len(s.items) >= s.capacity {
+		if !s.preemption {
+			s.observe(Observer.OnReject, SideTail)
+			return ErrDequeueFull
+		}
+		// вытесняем наименее приоритетный элемент - возможно, сам добавляемый
+		if s.cmp(item, s.items[0]) <= 0 {
+			s.evict(item)
+			return nil
+		}
+		s.evict(s.removeAt(0))
+	}
+	s.items = append(s.items, item)
+	s.bubbleUp(len(s.items) - 1)
+	s.observe(Observer.OnPush, SideTail)
+	return nil
+}
+func (s *PriorityDeque[T]) evict(item T) {
+	if s.onEvict != nil {
+		s.onEvict(item, SideHead)
+	}
+	s.observe(Observer.OnEvict, SideHead)
+}
+func (s *PriorityDeque[T]) observe(event func(Observer, DequeEvent), side Side) {
+	if s.observer != nil {
+		event(s.observer, DequeEvent{Side: side, Size: len(s.items), Capacity: s.capacity})
+	}
+}
+
+// region - min-max heap
+
+// maxIndex - индекс максимального элемента: корень или один из его потомков
+func (s *PriorityDeque[T]) maxIndex() int {
+	switch len(s.items) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if s.cmp(s.items[1], s.items[2]) >= 0 {
+		return 1
+	}
+	return 2
+}
+
+// removeAt - удаляет корень (минимум) или одного из его потомков (максимум)
+func (s *PriorityDeque[T]) removeAt(i int) T {
+	var empty T
+	last := len(s.items) - 1
+	item := s.items[i]
+	s.items[i] = s.items[last]
+	s.items[last] = empty // не удерживаем ссылку на извлечённый элемент
+	s.items = s.items[:last]
+	if i < last {
+		s.pushDown(i)
+	}
+	return item
+}
+
+// isMinLevel - элементы чётных уровней дерева не больше своих потомков, нечётных - не меньше
+func isMinLevel(i int) bool {
+	return bits.Len(uint(i+1))%2 == 1
+}
+func (s *PriorityDeque[T]) less(i, j int) bool {
+	return s.cmp(s.items[i], s.items[j]) < 0
+}
+func (s *PriorityDeque[T]) swap(i, j int) {
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+}
+
+func (s *PriorityDeque[T]) bubbleUp(i int) {
+	if i == 0 {
+		return
+	}
+	p := (i - 1) / 2
+	if isMinLevel(i) {
+		if s.less(p, i) {
+			s.swap(i, p)
+			s.bubbleUpLevels(p, func(a, b int) bool { return s.less(b, a) })
+		} else {
+			s.bubbleUpLevels(i, s.less)
+		}
+	} else {
+		if s.less(i, p) {
+			s.swap(i, p)
+			s.bubbleUpLevels(p, s.less)
+		} else {
+			s.bubbleUpLevels(i, func(a, b int) bool { return s.less(b, a) })
+		}
+	}
+}
+
+// bubbleUpLevels - поднимает элемент по уровням одного типа (через уровень),
+// пока before(элемент, прародитель)
+func (s *PriorityDeque[T]) bubbleUpLevels(i int, before func(a, b int) bool) {
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !before(i, g) {
+			return
+		}
+		s.swap(i, g)
+		i = g
+	}
+}
+
+func (s *PriorityDeque[T]) pushDown(i int) {
+	if isMinLevel(i) {
+		s.pushDownLevels(i, s.less)
+	} else {
+		s.pushDownLevels(i, func(a, b int) bool { return s.less(b, a) })
+	}
+}
+
+// pushDownLevels - опускает элемент по уровням одного типа, меняя его местами с
+// "первым" (в смысле before) из потомков и потомков потомков
+func (s *PriorityDeque[T]) pushDownLevels(i int, before func(a, b int) bool) {
+	n := len(s.items)
+	for {
+		first := 2*i + 1
+		if first >= n {
+			return
+		}
+		// ищем "первый" элемент среди детей и внуков
+		m := first
+		for _, c := range []int{2*i + 2, 4*i + 3, 4*i + 4, 4*i + 5, 4*i + 6} {
+			if c < n && before(c, m) {
+				m = c
+			}
+		}
+		if m <= 2*i+2 {
+			// ребёнок: достаточно одного обмена
+			if before(m, i) {
+				s.swap(m, i)
+			}
+			return
+		}
+		// внук: после обмена восстанавливаем порядок с его родителем
+		if !before(m, i) {
+			return
+		}
+		s.swap(m, i)
+		if p := (m - 1) / 2; before(p, m) {
+			s.swap(m, p)
+		}
+		i = m
+	}
+}
+
+// endregion
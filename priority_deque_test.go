@@ -0,0 +1,131 @@
+package container
+
+import (
+	"cmp"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestPriorityDequeBasic(t *testing.T) {
+
+	d := NewPriorityDeque[int](cmp.Compare[int])
+	assert.Equal(t, 0, d.Size())
+	assert.Equal(t, -1, d.Capacity())
+
+	_, err := d.PopMin()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+	_, err = d.PeekMax()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+
+	assert.NoError(t, d.PushAll(5, 1, 9, 3, 7))
+	assert.Equal(t, 5, d.Size())
+	assert.Equal(t, []int{1, 3, 5, 7, 9}, d.Values())
+
+	v, err := d.PeekMin()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, err = d.PeekMax()
+	assert.NoError(t, err)
+	assert.Equal(t, 9, v)
+
+	v, err = d.PopMax()
+	assert.NoError(t, err)
+	assert.Equal(t, 9, v)
+	v, err = d.PopMin()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, err = d.PopMax()
+	assert.NoError(t, err)
+	assert.Equal(t, 7, v)
+	assert.Equal(t, []int{3, 5}, d.Values())
+
+	d.Flush()
+	assert.Equal(t, 0, d.Size())
+
+}
+func TestPriorityDequeRandom(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+	d := NewPriorityDeque[int](cmp.Compare[int])
+	var expected []int
+
+	for i := 0; i < 10_000; i++ {
+		switch op := rnd.Intn(3); {
+		case op == 0 || len(expected) == 0:
+			v := rnd.Intn(1000)
+			assert.NoError(t, d.Push(v))
+			expected = append(expected, v)
+			slices.Sort(expected)
+		case op == 1:
+			v, err := d.PopMin()
+			assert.NoError(t, err)
+			assert.Equal(t, expected[0], v)
+			expected = expected[1:]
+		default:
+			v, err := d.PopMax()
+			assert.NoError(t, err)
+			assert.Equal(t, expected[len(expected)-1], v)
+			expected = expected[:len(expected)-1]
+		}
+		if !assert.Equal(t, len(expected), d.Size()) {
+			return
+		}
+	}
+	assert.Equal(t, expected, d.Values())
+
+}
+func TestBoundedPriorityDeque(t *testing.T) {
+
+	d := NewPriorityDeque[int](cmp.Compare[int],
+		DequeWithSizeLimit(3),
+	)
+	assert.Equal(t, 3, d.Capacity())
+	assert.ErrorIs(t, d.PushAll(5, 1, 9, 3), ErrDequeueFull)
+	assert.Equal(t, []int{1, 5, 9}, d.Values())
+
+	var evicted []int
+	d = NewPriorityDeque[int](cmp.Compare[int],
+		DequeWithSizeLimit(3),
+		DequeWithPreemption(),
+		DequeWithEvictionHandler(func(item int, side Side) {
+			assert.Equal(t, SideHead, side)
+			evicted = append(evicted, item)
+		}),
+	)
+	assert.NoError(t, d.PushAll(5, 1, 9, 3, 0, 7))
+	assert.Equal(t, []int{5, 7, 9}, d.Values())
+	assert.Equal(t, []int{1, 0, 3}, evicted)
+
+	// Observer: вытесненный сразу добавляемый элемент (0) не считается добавленным
+	c := NewDequeCounter()
+	d = NewPriorityDeque[int](cmp.Compare[int],
+		DequeWithSizeLimit(3),
+		DequeWithPreemption(),
+		DequeWithObserver(c),
+	)
+	assert.NoError(t, d.PushAll(5, 1, 9, 3, 0, 7))
+	stats := c.Stats()
+	assert.Equal(t, int64(5), stats.Pushes)
+	assert.Equal(t, int64(3), stats.Evictions)
+	assert.Equal(t, 3, stats.Length)
+	assert.Equal(t, 3, stats.HighWater)
+	assert.Equal(t, 3, stats.Capacity)
+
+	_, err := d.PopMax()
+	assert.NoError(t, err)
+	_, err = d.PopMin()
+	assert.NoError(t, err)
+	d.Flush()
+	stats = c.Stats()
+	assert.Equal(t, int64(3), stats.Pops)
+	assert.Equal(t, 0, stats.Length)
+
+	c = NewDequeCounter()
+	d = NewPriorityDeque[int](cmp.Compare[int], DequeWithSizeLimit(1), DequeWithObserver(c))
+	assert.NoError(t, d.Push(1))
+	assert.ErrorIs(t, d.Push(2), ErrDequeueFull)
+	assert.Equal(t, int64(1), c.Stats().Rejects)
+
+}
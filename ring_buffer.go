@@ -1,30 +1,101 @@
 package container
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"iter"
 	"sync"
 )
 
 var (
-	ErrEmptyBuffer = errors.New("empty buffer")
+	ErrEmptyBuffer     = errors.New("empty buffer")
+	ErrInvalidCapacity = errors.New("invalid capacity")
 )
 
+// region - options
+
+type ringBufferConfig struct {
+	fifo        bool
+	autoGrow    bool
+	maxCapacity int
+}
+
+type RingBufferOption func(*ringBufferConfig)
+
+// RingBufferWithFIFO - режим чтения "от старых к новым": Pop и Peek возвращают самый
+// старый элемент буфера, Values и All перечисляют элементы в порядке добавления.
+// По умолчанию (LIFO) читается последний добавленный элемент.
+func RingBufferWithFIFO() RingBufferOption {
+	return func(config *ringBufferConfig) {
+		config.fifo = true
+	}
+}
+
+// RingBufferWithAutoGrow - при записи в заполненный буфер удваивать его ёмкость
+// (но не больше чем до maxCapacity; 0 - без ограничения) вместо перезаписи старых
+// элементов; после достижения maxCapacity буфер перезаписывается как обычно
+func RingBufferWithAutoGrow(maxCapacity int) RingBufferOption {
+	return func(config *ringBufferConfig) {
+		config.autoGrow = true
+		config.maxCapacity = maxCapacity
+	}
+}
+
+// endregion
+
+// RingBuffer - кольцевой буфер; Pop и Peek возвращают последний добавленный элемент
+// (или самый старый в режиме RingBufferWithFIFO). Ёмкость задаётся при создании и может
+// быть изменена Resize. Все методы безопасны для конкурентного использования: каждая
+// операция выполняется атомарно под блокировкой буфера.
+//
+// Push в заполненный буфер (в любом режиме чтения) не блокируется, а перезаписывает
+// самый старый элемент (в режиме RingBufferWithAutoGrow - пока не достигнута
+// предельная ёмкость, буфер вместо этого увеличивается). Читатель при этом никогда не получает частично записанный
+// элемент и не получает один элемент дважды: каждый добавленный элемент либо
+// извлекается ровно одним вызовом Pop (или Drain), либо теряется при перезаписи -
+// количество потерянных элементов возвращает Overwritten. Values возвращает копию,
+// на которую последующая запись не влияет; итератор All удерживает блокировку
+// на чтение, и запись ждёт окончания перебора.
 type RingBuffer[T any] struct {
 	items        []T
+	seqs         []uint64 // Порядковые номера элементов (начиная с 1; 0 - пустая ячейка), нужны курсорам
 	capacity     int
 	size         int
-	readPointer  int
-	writePointer int
+	writePointer int           // Позиция, в которую будет записан следующий элемент
+	written      uint64        // Количество элементов, записанных за всё время (номер последнего из них)
+	fifo         bool          // Режим чтения: true - от старых к новым, false - от новых к старым
+	autoGrow     bool          // Увеличивать ёмкость заполненного буфера вместо перезаписи
+	maxCapacity  int           // Предельная ёмкость при автоматическом увеличении (0 - без ограничения)
+	overwritten  int           // Количество элементов, перезаписанных до извлечения
+	signal       chan struct{} // Закрывается при изменении содержимого буфера (для блокирующих операций)
+	closed       bool          // Буфер закрыт для записи
+	done         chan struct{} // Закрывается, когда буфер закрыт и пуст
+	signalMutex  sync.Mutex    // Защищает signal: ожидающие читатели получают его под блокировкой на чтение
 	mutex        sync.RWMutex
 }
 
-func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+// NewRingBuffer - создаёт буфер ёмкостью capacity; нулевая ёмкость допустима только
+// в режиме RingBufferWithAutoGrow (буфер увеличивается при первой записи), иначе,
+// как и при отрицательной ёмкости, конструктор паникует
+func NewRingBuffer[T any](capacity int, opts ...RingBufferOption) *RingBuffer[T] {
+	config := &ringBufferConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if capacity < 0 || capacity == 0 && !config.autoGrow {
+		panic(fmt.Sprintf("container: invalid ring buffer capacity %d", capacity))
+	}
 	return &RingBuffer[T]{
 		items:        make([]T, capacity),
+		seqs:         make([]uint64, capacity),
 		capacity:     capacity,
 		size:         0,
-		readPointer:  0,
 		writePointer: 0,
+		fifo:         config.fifo,
+		autoGrow:     config.autoGrow,
+		maxCapacity:  config.maxCapacity,
+		done:         make(chan struct{}),
 		mutex:        sync.RWMutex{},
 	}
 }
@@ -42,14 +113,52 @@ func (rb *RingBuffer[T]) Len() int {
 	return v
 }
 
+// FIFO - возвращает true, если буфер читается от старых элементов к новым
+func (rb *RingBuffer[T]) FIFO() bool {
+	return rb.fifo
+}
+
+// Overwritten - возвращает количество элементов, перезаписанных Push до того, как их
+// извлекли; сравнивая значения до и после чтения, читатель может обнаружить потерю данных
+func (rb *RingBuffer[T]) Overwritten() int {
+	rb.mutex.RLock()
+	v := rb.overwritten
+	rb.mutex.RUnlock()
+	return v
+}
+
+// Resize - изменяет ёмкость буфера; при уменьшении сохраняются самые новые элементы
+// (в прежнем порядке), а не поместившиеся старые считаются перезаписанными
+// (см. Overwritten). Курсоры продолжают чтение с прежнего места.
+func (rb *RingBuffer[T]) Resize(capacity int) error {
+	if capacity <= 0 {
+		return ErrInvalidCapacity
+	}
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	rb.resize(capacity)
+	return nil
+}
+
+// Push - добавляет элемент в буфер (при заполненном буфере перезаписывает самый
+// старый элемент); в закрытый буфер элемент не добавляется (чтобы узнать об этом,
+// используйте TryPush)
 func (rb *RingBuffer[T]) Push(item T) {
-	if rb.size < rb.capacity {
-		rb.size++
+	_ = rb.TryPush(item)
+}
+
+// TryPush - то же, что Push, но для закрытого буфера возвращает ErrClosed
+func (rb *RingBuffer[T]) TryPush(item T) error {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	if rb.closed {
+		return ErrClosed
 	}
-	rb.items[rb.writePointer] = item
-	rb.writePointer = rb.stepUp(rb.writePointer)
-	rb.readPointer = rb.stepDown(rb.writePointer)
+	rb.push(item)
+	return nil
 }
+
+// Peek - возвращает очередной (в порядке чтения) элемент, не извлекая его
 func (rb *RingBuffer[T]) Peek() (T, error) {
 	rb.mutex.RLock()
 	if rb.size == 0 {
@@ -57,10 +166,12 @@ func (rb *RingBuffer[T]) Peek() (T, error) {
 		var v T
 		return v, ErrEmptyBuffer
 	}
-	v := rb.items[rb.readPointer]
+	v := rb.items[rb.readPointer()]
 	rb.mutex.RUnlock()
 	return v, nil
 }
+
+// Pop - извлекает очередной (в порядке чтения) элемент
 func (rb *RingBuffer[T]) Pop() (T, error) {
 	rb.mutex.Lock()
 	if rb.size == 0 {
@@ -68,34 +179,235 @@ func (rb *RingBuffer[T]) Pop() (T, error) {
 		var v T
 		return v, ErrEmptyBuffer
 	}
-	item := rb.items[rb.readPointer]
-	rb.size--
-	rb.writePointer = rb.stepDown(rb.writePointer)
-	rb.readPointer = rb.stepDown(rb.writePointer)
+	item, _ := rb.pop()
 	rb.mutex.Unlock()
 	return item, nil
 }
+
+// Values - возвращает элементы буфера в порядке чтения (в котором их вернули бы
+// последовательные вызовы Pop)
 func (rb *RingBuffer[T]) Values() []T {
 	rb.mutex.RLock()
-	s := rb.readPointer
-	cnt := 0
 	res := make([]T, 0, rb.size)
-	for idx := s; cnt < rb.size; idx = rb.stepDown(idx) {
+	idx := rb.readPointer()
+	for cnt := 0; cnt < rb.size; cnt++ {
 		res = append(res, rb.items[idx])
-		cnt++
+		idx = rb.next(idx)
 	}
 	rb.mutex.RUnlock()
 	return res
 }
 
+// All - возвращает итератор по элементам буфера в том же порядке, что и Values().
+// Перебор выполняется "вживую" под блокировкой на чтение, без копирования элементов:
+// тело цикла не должно обращаться к буферу, а запись ждёт завершения перебора.
+func (rb *RingBuffer[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		rb.mutex.RLock()
+		defer rb.mutex.RUnlock()
+		idx := rb.readPointer()
+		for cnt := 0; cnt < rb.size; cnt++ {
+			if !yield(rb.items[idx]) {
+				return
+			}
+			idx = rb.next(idx)
+		}
+	}
+}
+
 func (rb *RingBuffer[T]) Flush() {
 	rb.mutex.Lock()
+	clear(rb.items)
+	clear(rb.seqs)
 	rb.size = 0
-	rb.readPointer = 0
 	rb.writePointer = 0
+	rb.notify()
 	rb.mutex.Unlock()
 }
 
+// Close - закрывает буфер: дальнейшие вызовы Push ничего не добавляют, а TryPush
+// завершаются ошибкой ErrClosed; оставшиеся элементы можно прочитать обычным образом. Повторный вызов ничего не делает.
+func (rb *RingBuffer[T]) Close() {
+	rb.mutex.Lock()
+	rb.closed = true
+	rb.notify()
+	rb.mutex.Unlock()
+}
+
+// Closed - возвращает true, если буфер закрыт
+func (rb *RingBuffer[T]) Closed() bool {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+	return rb.closed
+}
+
+// Done - возвращает канал, который закрывается, когда буфер закрыт и все
+// элементы из него извлечены
+func (rb *RingBuffer[T]) Done() <-chan struct{} {
+	return rb.done
+}
+
+func (rb *RingBuffer[T]) push(item T) {
+	if rb.size >= rb.capacity && rb.autoGrow && (rb.maxCapacity <= 0 || rb.capacity < rb.maxCapacity) {
+		c := max(1, 2*rb.capacity)
+		if rb.maxCapacity > 0 {
+			c = min(c, rb.maxCapacity)
+		}
+		rb.resize(c)
+	}
+	if rb.size < rb.capacity {
+		rb.size++
+	} else {
+		rb.overwritten++
+	}
+	rb.written++
+	rb.items[rb.writePointer] = item
+	rb.seqs[rb.writePointer] = rb.written
+	rb.writePointer = rb.stepUp(rb.writePointer)
+	rb.notify()
+}
+
+// pop - извлекает очередной элемент; возвращает его и его порядковый номер (для restore)
+func (rb *RingBuffer[T]) pop() (T, uint64) {
+	idx := rb.readPointer()
+	item, seq := rb.items[idx], rb.seqs[idx]
+	var empty T
+	rb.items[idx] = empty // не удерживаем ссылку на извлечённый элемент
+	rb.seqs[idx] = 0
+	rb.size--
+	if !rb.fifo {
+		rb.writePointer = idx
+	}
+	rb.notify()
+	return item, seq
+}
+
+// restore - возвращает извлечённый элемент на прежнее место (в начало порядка
+// чтения), если в буфере осталось место. Элемент сохраняет порядковый номер, поэтому
+// курсоры не читают его повторно; исключение - режим LIFO, если после извлечения
+// в буфер записывались новые элементы: тогда элемент считается записанным заново.
+func (rb *RingBuffer[T]) restore(item T, seq uint64) {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	if rb.size >= rb.capacity {
+		return
+	}
+	idx := rb.stepDown(rb.oldest())
+	if !rb.fifo {
+		idx = rb.writePointer
+		if rb.size > 0 && rb.seqs[rb.stepDown(idx)] > seq {
+			rb.written++
+			seq = rb.written
+		}
+		rb.writePointer = rb.stepUp(idx)
+	}
+	rb.items[idx] = item
+	rb.seqs[idx] = seq
+	rb.size++
+	rb.notify()
+}
+
+// resize - переносит элементы (от старых к новым) в буфер новой ёмкости, отбрасывая
+// самые старые из не поместившихся
+func (rb *RingBuffer[T]) resize(capacity int) {
+	items := make([]T, capacity)
+	seqs := make([]uint64, capacity)
+	size := min(rb.size, capacity)
+	rb.overwritten += rb.size - size
+	if size > 0 {
+		idx := (rb.oldest() + rb.size - size) % rb.capacity
+		for i := 0; i < size; i++ {
+			items[i], seqs[i] = rb.items[idx], rb.seqs[idx]
+			idx = rb.stepUp(idx)
+		}
+	}
+	rb.items, rb.seqs = items, seqs
+	rb.capacity = capacity
+	rb.size = size
+	rb.writePointer = size % capacity
+	rb.notify()
+}
+
+// popWait - извлекает элемент; если буфер пуст, ожидает появления элемента, отмены
+// контекста (возвращает ctx.Err()) или закрытия буфера (возвращает ErrClosed)
+// (порядковый номер элемента нужен для restore)
+func (rb *RingBuffer[T]) popWait(ctx context.Context) (T, uint64, error) {
+	for {
+		rb.mutex.Lock()
+		if rb.size > 0 {
+			item, seq := rb.pop()
+			rb.mutex.Unlock()
+			return item, seq, nil
+		}
+		if rb.closed {
+			rb.mutex.Unlock()
+			var v T
+			return v, 0, ErrClosed
+		}
+		changed := rb.changed()
+		rb.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			var v T
+			return v, 0, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// changed - возвращает канал, который будет закрыт при следующем изменении буфера;
+// вызывается под блокировкой буфера (достаточно блокировки на чтение: канал создаётся
+// под signalMutex, а notify вызывается только под блокировкой на запись, поэтому
+// изменение после проверки состояния не будет пропущено)
+func (rb *RingBuffer[T]) changed() <-chan struct{} {
+	rb.signalMutex.Lock()
+	defer rb.signalMutex.Unlock()
+	if rb.signal == nil {
+		rb.signal = make(chan struct{})
+	}
+	return rb.signal
+}
+
+// notify - будит всех ожидающих изменения буфера и, если закрытый буфер опустел,
+// закрывает канал Done(); вызывается под блокировкой на запись
+func (rb *RingBuffer[T]) notify() {
+	rb.signalMutex.Lock()
+	if rb.signal != nil {
+		close(rb.signal)
+		rb.signal = nil
+	}
+	rb.signalMutex.Unlock()
+	if rb.closed && rb.size == 0 {
+		select {
+		case <-rb.done:
+		default:
+			close(rb.done)
+		}
+	}
+}
+
+// oldest - позиция самого старого элемента буфера
+func (rb *RingBuffer[T]) oldest() int {
+	return (rb.writePointer - rb.size + rb.capacity) % rb.capacity
+}
+
+// readPointer - позиция элемента, который будет прочитан следующим
+func (rb *RingBuffer[T]) readPointer() int {
+	if rb.fifo {
+		return rb.oldest()
+	}
+	return rb.stepDown(rb.writePointer)
+}
+
+// next - позиция элемента, следующего за idx в порядке чтения
+func (rb *RingBuffer[T]) next(idx int) int {
+	if rb.fifo {
+		return rb.stepUp(idx)
+	}
+	return rb.stepDown(idx)
+}
+
 func (rb *RingBuffer[T]) stepDown(idx int) int {
 	idx = idx - 1
 	if idx < 0 {
@@ -1,9 +1,14 @@
 package container
 
 import (
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"runtime"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestRingBufferCreate(t *testing.T) {
@@ -87,6 +92,7 @@ func TestRingBufferPushPeekPopOverflow(t *testing.T) {
 	for _, v := range rb.Values() {
 		fmt.Println(v)
 	}
+	assert.Equal(t, 4, rb.Overwritten())
 
 	v, err = rb.Pop()
 	assert.NoError(t, err)
@@ -104,3 +110,275 @@ func TestRingBufferPushPeekPopOverflow(t *testing.T) {
 	assert.Equal(t, 0, rb.Len())
 
 }
+func TestRingBufferAll(t *testing.T) {
+
+	rb := NewRingBuffer[int](3)
+	assert.Empty(t, slices.Collect(rb.All()))
+
+	for i := 1; i <= 5; i++ {
+		rb.Push(i)
+	}
+	assert.Equal(t, rb.Values(), slices.Collect(rb.All()))
+	assert.Equal(t, []int{5, 4, 3}, slices.Collect(rb.All()))
+
+}
+func TestRingBufferFIFO(t *testing.T) {
+
+	rb := NewRingBuffer[int](3, RingBufferWithFIFO())
+	assert.True(t, rb.FIFO())
+	for i := 1; i <= 5; i++ {
+		rb.Push(i)
+	}
+	assert.Equal(t, []int{3, 4, 5}, rb.Values())
+	assert.Equal(t, rb.Values(), slices.Collect(rb.All()))
+	assert.Equal(t, 2, rb.Overwritten())
+
+	v, err := rb.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+	v, err = rb.Pop()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+
+	rb.Push(6)
+	rb.Push(7) // 4 перезаписывается
+	assert.Equal(t, []int{5, 6, 7}, rb.Values())
+	assert.Equal(t, 3, rb.Overwritten())
+	for _, expected := range []int{5, 6, 7} {
+		v, err := rb.Pop()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+	_, err = rb.Pop()
+	assert.ErrorIs(t, err, ErrEmptyBuffer)
+
+	// элемент, возвращённый Drain при отмене, снова читается первым
+	rb.Push(8)
+	rb.Push(9)
+	v, seq, err := rb.popWait(context.Background())
+	assert.NoError(t, err)
+	rb.restore(v, seq)
+	assert.Equal(t, []int{8, 9}, rb.Values())
+
+	lifo := NewRingBuffer[int](3)
+	lifo.Push(1)
+	lifo.Push(2)
+	v, seq, err = lifo.popWait(context.Background())
+	assert.NoError(t, err)
+	lifo.restore(v, seq)
+	assert.Equal(t, []int{2, 1}, lifo.Values())
+
+}
+func TestRingBufferClose(t *testing.T) {
+
+	rb := NewRingBuffer[int](3)
+	rb.Push(1)
+	rb.Push(2)
+
+	rb.Close()
+	assert.True(t, rb.Closed())
+	rb.Push(3) // в закрытый буфер не добавляется
+	assert.ErrorIs(t, rb.TryPush(3), ErrClosed)
+	assert.Equal(t, 2, rb.Len())
+
+	select {
+	case <-rb.Done():
+		assert.Fail(t, "closed buffer is not drained yet")
+	default:
+	}
+
+	v, err := rb.Pop()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	v, err = rb.Pop()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	select {
+	case <-rb.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "drained buffer is not done")
+	}
+
+}
+func TestRingBufferConcurrent(t *testing.T) {
+	for name, opts := range map[string][]RingBufferOption{"lifo": nil, "fifo": {RingBufferWithFIFO()}} {
+		t.Run(name, func(t *testing.T) {
+			testRingBufferConcurrent(t, NewRingBuffer[int](64, opts...))
+		})
+	}
+
+}
+func testRingBufferConcurrent(t *testing.T, rb *RingBuffer[int]) {
+
+	const (
+		producers = 8
+		consumers = 8
+		perWorker = 5000
+	)
+
+	// потребители используют Pop и Drain; каждый добавленный элемент должен быть либо
+	// извлечён ровно один раз, либо перезаписан, либо остаться в буфере
+	popped := make([][]int, consumers)
+
+	var producing, consuming sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		producing.Add(1)
+		go func() {
+			defer producing.Done()
+			for i := 0; i < perWorker; i++ {
+				rb.Push(p*perWorker + i)
+			}
+		}()
+	}
+	for c := 0; c < consumers; c++ {
+		consuming.Add(1)
+		go func() {
+			defer consuming.Done()
+			if c%2 == 0 {
+				for v := range rb.Drain(context.Background()) {
+					popped[c] = append(popped[c], v)
+				}
+				return
+			}
+			for {
+				v, err := rb.Pop()
+				if err == nil {
+					popped[c] = append(popped[c], v)
+					continue
+				}
+				if rb.Closed() {
+					return
+				}
+				runtime.Gosched()
+			}
+		}()
+	}
+
+	// конкурентные читатели видят согласованное состояние
+	stop := make(chan struct{})
+	var reading sync.WaitGroup
+	reading.Add(1)
+	go func() {
+		defer reading.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			values := rb.Values()
+			assert.LessOrEqual(t, len(values), rb.Cap())
+			assert.LessOrEqual(t, rb.Len(), rb.Cap())
+			n := 0
+			for range rb.All() {
+				n++
+			}
+			assert.LessOrEqual(t, n, rb.Cap())
+			_, _ = rb.Peek()
+		}
+	}()
+
+	producing.Wait()
+	rb.Close()
+	consuming.Wait()
+	close(stop)
+	reading.Wait()
+
+	seen := make(map[int]bool, producers*perWorker)
+	for _, values := range popped {
+		for _, v := range values {
+			assert.False(t, seen[v], "item %d popped twice", v)
+			assert.True(t, v >= 0 && v < producers*perWorker, "unknown item %d", v)
+			seen[v] = true
+		}
+	}
+	assert.Equal(t, producers*perWorker, len(seen)+rb.Len()+rb.Overwritten())
+	assert.Equal(t, 0, rb.Len())
+
+}
+func TestRingBufferResize(t *testing.T) {
+
+	for name, opts := range map[string][]RingBufferOption{"lifo": nil, "fifo": {RingBufferWithFIFO()}} {
+		t.Run(name, func(t *testing.T) {
+
+			rb := NewRingBuffer[int](4, opts...)
+			for i := 1; i <= 6; i++ { // 1, 2 перезаписываются, начало буфера сдвинуто
+				rb.Push(i)
+			}
+			values := rb.Values()
+			c := rb.NewCursor()
+
+			assert.ErrorIs(t, rb.Resize(0), ErrInvalidCapacity)
+
+			// увеличение сохраняет все элементы и их порядок
+			assert.NoError(t, rb.Resize(8))
+			assert.Equal(t, 8, rb.Cap())
+			assert.Equal(t, values, rb.Values())
+			for i := 7; i <= 10; i++ {
+				rb.Push(i)
+			}
+			assert.Equal(t, 8, rb.Len())
+			assert.Equal(t, 2, rb.Overwritten())
+
+			// уменьшение сохраняет самые новые элементы
+			assert.NoError(t, rb.Resize(3))
+			assert.Equal(t, 3, rb.Len())
+			assert.Equal(t, 7, rb.Overwritten())
+			expected := []int{8, 9, 10}
+			if !rb.FIFO() {
+				expected = []int{10, 9, 8}
+			}
+			assert.Equal(t, expected, rb.Values())
+
+			// курсор сообщает об элементах, отброшенных при уменьшении
+			v, missed, err := c.Next()
+			assert.NoError(t, err)
+			assert.Equal(t, 8, v)
+			assert.Equal(t, 5, missed)
+
+			rb.Push(11)
+			assert.Equal(t, 3, rb.Len())
+			v, err = rb.Peek()
+			assert.NoError(t, err)
+			if rb.FIFO() {
+				assert.Equal(t, 9, v)
+			} else {
+				assert.Equal(t, 11, v)
+			}
+
+		})
+	}
+
+}
+func TestRingBufferAutoGrow(t *testing.T) {
+
+	rb := NewRingBuffer[int](2, RingBufferWithFIFO(), RingBufferWithAutoGrow(5))
+	for i := 1; i <= 5; i++ {
+		rb.Push(i)
+	}
+	assert.Equal(t, 5, rb.Cap()) // 2 -> 4 -> 5
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, rb.Values())
+	assert.Equal(t, 0, rb.Overwritten())
+
+	// после достижения предела буфер перезаписывается
+	rb.Push(6)
+	assert.Equal(t, 5, rb.Cap())
+	assert.Equal(t, []int{2, 3, 4, 5, 6}, rb.Values())
+	assert.Equal(t, 1, rb.Overwritten())
+
+	// нулевая ёмкость допустима только с автоматическим увеличением
+	assert.Panics(t, func() { NewRingBuffer[int](0) })
+	assert.Panics(t, func() { NewRingBuffer[int](-1, RingBufferWithAutoGrow(0)) })
+
+	unlimited := NewRingBuffer[int](0, RingBufferWithAutoGrow(0))
+	for i := 1; i <= 100; i++ {
+		unlimited.Push(i)
+	}
+	assert.Equal(t, 100, unlimited.Len())
+	assert.Equal(t, 128, unlimited.Cap())
+	v, err := unlimited.Pop()
+	assert.NoError(t, err)
+	assert.Equal(t, 100, v)
+
+}
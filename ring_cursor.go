@@ -0,0 +1,107 @@
+package container
+
+import (
+	"context"
+	"sort"
+)
+
+// RingCursor - независимый читатель RingBuffer: читает элементы в порядке добавления
+// (независимо от режима чтения буфера), не извлекая их, поэтому несколько курсоров
+// могут читать один поток, каждый в своём темпе. Курсор не задерживает запись: если
+// писатель перезаписал элементы (или их извлекли Pop) до того, как курсор до них
+// дошёл, курсор пропускает их и сообщает их количество. Один курсор предназначен
+// для использования из одной горутины.
+type RingCursor[T any] struct {
+	rb     *RingBuffer[T]
+	next   uint64 // Порядковый номер следующего элемента, который должен прочитать курсор
+	missed int    // Общее количество пропущенных элементов
+}
+
+// NewCursor - создаёт курсор, начинающий чтение с самого старого элемента буфера
+func (rb *RingBuffer[T]) NewCursor() *RingCursor[T] {
+	rb.mutex.RLock()
+	defer rb.mutex.RUnlock()
+	c := &RingCursor[T]{
+		rb:   rb,
+		next: rb.written + 1,
+	}
+	if rb.size > 0 {
+		c.next = rb.seqs[rb.oldest()]
+	}
+	return c
+}
+
+// Next - возвращает следующий непрочитанный элемент и количество элементов, пропущенных
+// курсором непосредственно перед ним; если новых элементов нет, возвращает ErrEmptyBuffer
+func (c *RingCursor[T]) Next() (T, int, error) {
+	c.rb.mutex.RLock()
+	defer c.rb.mutex.RUnlock()
+	return c.read()
+}
+
+// NextWait - то же, что Next, но при отсутствии новых элементов ожидает их появления,
+// отмены контекста (возвращает ctx.Err()) или закрытия буфера (возвращает ErrClosed)
+func (c *RingCursor[T]) NextWait(ctx context.Context) (T, int, error) {
+	rb := c.rb
+	for {
+		// курсоры читают под блокировкой на чтение и не мешают друг другу
+		rb.mutex.RLock()
+		item, missed, err := c.read()
+		if err == nil {
+			rb.mutex.RUnlock()
+			return item, missed, nil
+		}
+		if rb.closed {
+			rb.mutex.RUnlock()
+			return item, 0, ErrClosed
+		}
+		changed := rb.changed()
+		rb.mutex.RUnlock()
+
+		select {
+		case <-ctx.Done():
+			var empty T
+			return empty, 0, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Lag - возвращает количество элементов буфера, которые курсор ещё не прочитал
+func (c *RingCursor[T]) Lag() int {
+	c.rb.mutex.RLock()
+	defer c.rb.mutex.RUnlock()
+	return c.rb.size - c.search()
+}
+
+// Missed - возвращает общее количество элементов, пропущенных курсором
+func (c *RingCursor[T]) Missed() int {
+	return c.missed
+}
+
+// read - читает следующий элемент; вызывается под блокировкой буфера
+func (c *RingCursor[T]) read() (T, int, error) {
+	rb := c.rb
+	k := c.search()
+	if k == rb.size {
+		var empty T
+		return empty, 0, ErrEmptyBuffer
+	}
+	idx := (rb.oldest() + k) % rb.capacity
+	seq := rb.seqs[idx]
+	missed := int(seq - c.next)
+	c.missed += missed
+	c.next = seq + 1
+	return rb.items[idx], missed, nil
+}
+
+// search - номер (от самого старого элемента) первого элемента буфера, который курсор
+// ещё не прочитал: от старых элементов к новым порядковые номера возрастают
+// в любом режиме чтения
+func (c *RingCursor[T]) search() int {
+	rb := c.rb
+	oldest := rb.oldest()
+	return sort.Search(rb.size, func(k int) bool {
+		return rb.seqs[(oldest+k)%rb.capacity] >= c.next
+	})
+}
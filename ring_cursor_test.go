@@ -0,0 +1,111 @@
+package container
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestRingCursor(t *testing.T) {
+
+	for name, opts := range map[string][]RingBufferOption{"lifo": nil, "fifo": {RingBufferWithFIFO()}} {
+		t.Run(name, func(t *testing.T) {
+
+			rb := NewRingBuffer[int](4, opts...)
+			rb.Push(1)
+			rb.Push(2)
+
+			c1, c2 := rb.NewCursor(), rb.NewCursor()
+			for _, expected := range []int{1, 2} {
+				v, missed, err := c1.Next()
+				assert.NoError(t, err)
+				assert.Equal(t, expected, v)
+				assert.Equal(t, 0, missed)
+			}
+			_, _, err := c1.Next()
+			assert.ErrorIs(t, err, ErrEmptyBuffer)
+			assert.Equal(t, 2, c2.Lag())
+
+			// курсоры не извлекают элементы и не мешают записи
+			assert.Equal(t, 2, rb.Len())
+			for i := 3; i <= 10; i++ {
+				rb.Push(i)
+			}
+			v, missed, err := c1.Next() // 3..6 перезаписаны
+			assert.NoError(t, err)
+			assert.Equal(t, 7, v)
+			assert.Equal(t, 4, missed)
+			v, missed, err = c2.Next() // 1..6 перезаписаны
+			assert.NoError(t, err)
+			assert.Equal(t, 7, v)
+			assert.Equal(t, 6, missed)
+			assert.Equal(t, 6, c2.Missed())
+			assert.Equal(t, 3, c2.Lag())
+
+			// элементы, извлечённые до того, как курсор их прочитал, тоже пропускаются
+			// (о пропуске становится известно при чтении следующего элемента)
+			_, err = rb.Pop()
+			assert.NoError(t, err)
+			rb.Push(11)
+			read := 3 // 1, 2, 7
+			for {
+				v, _, err = c1.Next()
+				if err != nil {
+					break
+				}
+				read++
+			}
+			assert.Equal(t, 11, read+c1.Missed())
+
+			// новый курсор читает с самого старого элемента
+			c3 := rb.NewCursor()
+			assert.Equal(t, rb.Len(), c3.Lag())
+			assert.Equal(t, 0, c3.Missed())
+
+		})
+	}
+
+}
+
+func TestRingCursorConcurrent(t *testing.T) {
+
+	const (
+		items   = 20_000
+		readers = 4
+	)
+
+	// каждый курсор читает элементы по возрастанию, а прочитанные и пропущенные
+	// вместе составляют весь поток
+	rb := NewRingBuffer[int](32)
+	cursors := make([]*RingCursor[int], readers)
+	for i := range cursors {
+		cursors[i] = rb.NewCursor()
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range cursors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last, read := 0, 0
+			for {
+				v, _, err := c.NextWait(context.Background())
+				if err != nil {
+					assert.ErrorIs(t, err, ErrClosed)
+					break
+				}
+				assert.Greater(t, v, last)
+				last = v
+				read++
+			}
+			assert.Equal(t, items, read+c.Missed())
+		}()
+	}
+	for i := 1; i <= items; i++ {
+		rb.Push(i)
+	}
+	rb.Close()
+	wg.Wait()
+
+}
@@ -1,6 +1,7 @@
 package container
 
 import (
+	"iter"
 	"sync"
 )
 
@@ -19,6 +20,15 @@ func NewSet[T comparable](items ...T) *Set[T] {
 	return set
 }
 
+// SetFrom - создаёт множество из элементов последовательности
+func SetFrom[T comparable](seq iter.Seq[T]) *Set[T] {
+	set := NewSet[T]()
+	for v := range seq {
+		set.values[v] = struct{}{}
+	}
+	return set
+}
+
 func (s *Set[T]) Add(value ...T) *Set[T] {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -64,6 +74,21 @@ func (s *Set[T]) Values() []T {
 	return values
 }
 
+// All - возвращает итератор по элементам множества (в произвольном порядке).
+// Перебор выполняется "вживую" под блокировкой на чтение, без копирования элементов:
+// тело цикла не должно обращаться к множеству, а запись ждёт завершения перебора.
+func (s *Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		for v := range s.values {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
 func (s *Set[T]) RemoveAll(other *Set[T]) *Set[T] {
 	result := NewSet[T]()
 	for v := range s.values {
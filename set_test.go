@@ -0,0 +1,31 @@
+package container
+
+import (
+	"github.com/stretchr/testify/assert"
+	"slices"
+	"testing"
+)
+
+func TestSetIterators(t *testing.T) {
+
+	s := SetFrom(slices.Values([]int{1, 2, 3, 2, 1}))
+	assert.Equal(t, 3, s.Len())
+	assert.ElementsMatch(t, []int{1, 2, 3}, slices.Collect(s.All()))
+
+	var seen []int
+	for v := range s.All() {
+		seen = append(seen, v)
+		if len(seen) == 2 {
+			break
+		}
+	}
+	assert.Len(t, seen, 2)
+	assert.Subset(t, []int{1, 2, 3}, seen)
+
+	// после прерванного перебора множество доступно на запись
+	s.Add(4)
+	assert.ElementsMatch(t, []int{1, 2, 3, 4}, slices.Collect(s.All()))
+
+	assert.Empty(t, slices.Collect(SetFrom(slices.Values([]int(nil))).All()))
+
+}
@@ -0,0 +1,119 @@
+package container
+
+import (
+	"context"
+)
+
+// Stack - стек (LIFO) поверх Deque: вершина стека - голова очереди. Принимает те же
+// опции, что и Deque; при вытеснении из заполненного стека удаляется самый старый
+// элемент (дно стека).
+type Stack[T any] struct {
+	deque *Deque[T]
+}
+
+func NewStack[T any](opts ...DequeOption) *Stack[T] {
+	return &Stack[T]{
+		deque: NewDeque[T](opts...),
+	}
+}
+
+// Push - кладёт элементы на вершину стека по очереди (последний окажется на вершине)
+func (s *Stack[T]) Push(items ...T) error {
+	return s.deque.Push(SideHead, items...)
+}
+
+// PushWait - кладёт элемент на вершину стека, ожидая освобождения места
+func (s *Stack[T]) PushWait(ctx context.Context, item T) error {
+	return s.deque.PushWait(ctx, SideHead, item)
+}
+
+// Pop - снимает элемент с вершины стека
+func (s *Stack[T]) Pop() (T, error) {
+	return s.deque.Pop(SideHead)
+}
+
+// PopWait - снимает элемент с вершины стека, ожидая его появления
+func (s *Stack[T]) PopWait(ctx context.Context) (T, error) {
+	return s.deque.PopWait(ctx, SideHead)
+}
+
+// Peek - возвращает элемент с вершины стека, не снимая его
+func (s *Stack[T]) Peek() (T, error) {
+	return s.deque.Peek(SideHead)
+}
+
+func (s *Stack[T]) Flush() {
+	s.deque.Flush()
+}
+
+// Values - возвращает элементы стека от вершины ко дну
+func (s *Stack[T]) Values() []T {
+	return s.deque.Values()
+}
+
+// Size - возвращает количество элементов в стеке
+func (s *Stack[T]) Size() int {
+	return s.deque.Size()
+}
+
+// Capacity - возвращает максимальный размер стека; -1 для неограниченного стека
+func (s *Stack[T]) Capacity() int {
+	return s.deque.Capacity()
+}
+
+// Queue - очередь (FIFO) поверх Deque: элементы добавляются в хвост и извлекаются из
+// головы. Принимает те же опции, что и Deque; при вытеснении из заполненной очереди
+// удаляется самый старый элемент (голова).
+type Queue[T any] struct {
+	deque *Deque[T]
+}
+
+func NewQueue[T any](opts ...DequeOption) *Queue[T] {
+	return &Queue[T]{
+		deque: NewDeque[T](opts...),
+	}
+}
+
+// Push - добавляет элементы в конец очереди по порядку
+func (s *Queue[T]) Push(items ...T) error {
+	return s.deque.Push(SideTail, items...)
+}
+
+// PushWait - добавляет элемент в конец очереди, ожидая освобождения места
+func (s *Queue[T]) PushWait(ctx context.Context, item T) error {
+	return s.deque.PushWait(ctx, SideTail, item)
+}
+
+// Pop - извлекает элемент из начала очереди
+func (s *Queue[T]) Pop() (T, error) {
+	return s.deque.Pop(SideHead)
+}
+
+// PopWait - извлекает элемент из начала очереди, ожидая его появления
+func (s *Queue[T]) PopWait(ctx context.Context) (T, error) {
+	return s.deque.PopWait(ctx, SideHead)
+}
+
+// Peek - возвращает элемент из начала очереди, не извлекая его
+func (s *Queue[T]) Peek() (T, error) {
+	return s.deque.Peek(SideHead)
+}
+
+func (s *Queue[T]) Flush() {
+	s.deque.Flush()
+}
+
+// Values - возвращает элементы очереди в порядке извлечения
+func (s *Queue[T]) Values() []T {
+	return s.deque.Values()
+}
+
+// Size - возвращает количество элементов в очереди
+func (s *Queue[T]) Size() int {
+	return s.deque.Size()
+}
+
+// Capacity - возвращает максимальный размер очереди; -1 для неограниченной очереди
+func (s *Queue[T]) Capacity() int {
+	return s.deque.Capacity()
+}
@@ -0,0 +1,56 @@
+package container
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+
+	s := NewStack[int](DequeWithSizeLimit(3), DequeWithPreemption())
+	_, err := s.Pop()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+
+	assert.NoError(t, s.Push(1, 2, 3, 4)) // дно стека (1) вытесняется
+	assert.Equal(t, []int{4, 3, 2}, s.Values())
+	assert.Equal(t, 3, s.Size())
+	assert.Equal(t, 3, s.Capacity())
+
+	v, err := s.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, v)
+	for _, expected := range []int{4, 3, 2} {
+		v, err := s.Pop()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+
+	assert.NoError(t, s.Push(5))
+	s.Flush()
+	assert.Equal(t, 0, s.Size())
+
+}
+
+func TestQueue(t *testing.T) {
+
+	q := NewQueue[int](DequeWithSizeLimit(3), DequeWithPreemption())
+	_, err := q.Pop()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+
+	assert.NoError(t, q.Push(1, 2, 3, 4)) // начало очереди (1) вытесняется
+	assert.Equal(t, []int{2, 3, 4}, q.Values())
+
+	v, err := q.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	for _, expected := range []int{2, 3, 4} {
+		v, err := q.Pop()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v)
+	}
+
+	q = NewQueue[int](DequeWithSizeLimit(1))
+	assert.NoError(t, q.Push(1))
+	assert.ErrorIs(t, q.Push(2), ErrDequeueFull)
+
+}
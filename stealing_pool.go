@@ -0,0 +1,185 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+)
+
+// region - options
+
+type stealingPoolConfig struct {
+	selector     VictimSelector
+	dequeOptions []DequeOption
+}
+type StealingPoolOption func(*stealingPoolConfig)
+
+// StealingPoolWithVictimSelector - стратегия выбора воркеров, у которых крадутся задачи
+// (по умолчанию - RandomVictims)
+func StealingPoolWithVictimSelector(selector VictimSelector) StealingPoolOption {
+	return func(config *stealingPoolConfig) {
+		config.selector = selector
+	}
+}
+
+// StealingPoolWithDequeOptions - опции очередей воркеров (ограничение размера, вытеснение и т.п.)
+func StealingPoolWithDequeOptions(opts ...DequeOption) StealingPoolOption {
+	return func(config *stealingPoolConfig) {
+		config.dequeOptions = append(config.dequeOptions, opts...)
+	}
+}
+
+// endregion
+// region - victim selection
+
+// VictimSelector - стратегия кражи: возвращает воркеров (кроме самого вора thief),
+// в порядке которых вор пытается украсть задачу; size возвращает текущий размер
+// очереди воркера
+type VictimSelector func(thief, workers int, size func(worker int) int) iter.Seq[int]
+
+// RoundRobinVictims - обходит воркеров по кругу, начиная со следующего за вором
+func RoundRobinVictims() VictimSelector {
+	return func(thief, workers int, _ func(int) int) iter.Seq[int] {
+		return cyclicVictims(thief, workers, thief+1)
+	}
+}
+
+// RandomVictims - обходит воркеров по кругу, начиная со случайного
+func RandomVictims() VictimSelector {
+	return func(thief, workers int, _ func(int) int) iter.Seq[int] {
+		return cyclicVictims(thief, workers, rand.IntN(workers))
+	}
+}
+
+// LargestVictim - выбирает воркера с самой длинной очередью
+func LargestVictim() VictimSelector {
+	return func(thief, workers int, size func(int) int) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			victim, largest := -1, 0
+			for w := 0; w < workers; w++ {
+				if n := size(w); w != thief && n > largest {
+					victim, largest = w, n
+				}
+			}
+			if victim >= 0 {
+				yield(victim)
+			}
+		}
+	}
+}
+
+func cyclicVictims(thief, workers, start int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i < workers; i++ {
+			w := (start + i) % workers
+			if w != thief && !yield(w) {
+				return
+			}
+		}
+	}
+}
+
+// endregion
+
+// StealingPool - набор очередей воркеров для планировщика с кражей задач: каждый
+// воркер добавляет задачи в голову своей очереди и извлекает их оттуда же (LIFO),
+// а при отсутствии своих задач крадёт самые старые задачи из хвостов очередей
+// других воркеров. Очереди воркеров - ConcurrentDeque, поэтому владелец очереди
+// и воры не конкурируют за одну блокировку.
+type StealingPool[T any] struct {
+	deques   []*ConcurrentDeque[T]
+	selector VictimSelector
+}
+
+// NewStealingPool - создаёт пул из workers очередей; при workers <= 0 конструктор паникует
+func NewStealingPool[T any](workers int, opts ...StealingPoolOption) *StealingPool[T] {
+
+	if workers <= 0 {
+		panic(fmt.Sprintf("container: invalid stealing pool worker count %d", workers))
+	}
+
+	pc := &stealingPoolConfig{
+		selector: RandomVictims(),
+	}
+	for _, opt := range opts {
+		opt(pc)
+	}
+
+	deques := make([]*ConcurrentDeque[T], workers)
+	for i := range deques {
+		deques[i] = NewConcurrentDeque[T](pc.dequeOptions...)
+	}
+
+	return &StealingPool[T]{
+		deques:   deques,
+		selector: pc.selector,
+	}
+
+}
+
+// Push - добавляет задачу в очередь воркера
+func (p *StealingPool[T]) Push(worker int, task T) error {
+	if worker < 0 || worker >= len(p.deques) {
+		return ErrOutOfRange
+	}
+	return p.deques[worker].PushHead(task)
+}
+
+// Pop - извлекает задачу из очереди воркера (последнюю добавленную)
+func (p *StealingPool[T]) Pop(worker int) (T, error) {
+	if worker < 0 || worker >= len(p.deques) {
+		var empty T
+		return empty, ErrOutOfRange
+	}
+	return p.deques[worker].PopHead()
+}
+
+// Steal - крадёт для воркера самую старую задачу у одного из других воркеров
+// (в порядке, заданном стратегией выбора); ErrDequeueEmpty, если украсть нечего
+func (p *StealingPool[T]) Steal(worker int) (T, error) {
+	var empty T
+	if worker < 0 || worker >= len(p.deques) {
+		return empty, ErrOutOfRange
+	}
+	for victim := range p.selector(worker, len(p.deques), p.Size) {
+		if victim == worker || victim < 0 || victim >= len(p.deques) {
+			continue
+		}
+		if task, err := p.deques[victim].PopTail(); err == nil {
+			return task, nil
+		}
+	}
+	return empty, ErrDequeueEmpty
+}
+
+// PopOrSteal - извлекает задачу из очереди воркера, а если она пуста - крадёт
+func (p *StealingPool[T]) PopOrSteal(worker int) (T, error) {
+	task, err := p.Pop(worker)
+	if !errors.Is(err, ErrDequeueEmpty) {
+		return task, err
+	}
+	return p.Steal(worker)
+}
+
+// Workers - возвращает количество воркеров
+func (p *StealingPool[T]) Workers() int {
+	return len(p.deques)
+}
+
+// Size - возвращает количество задач в очереди воркера
+func (p *StealingPool[T]) Size(worker int) int {
+	if worker < 0 || worker >= len(p.deques) {
+		return 0
+	}
+	return p.deques[worker].Size()
+}
+
+// Total - возвращает общее количество задач во всех очередях
+func (p *StealingPool[T]) Total() int {
+	total := 0
+	for _, d := range p.deques {
+		total += d.Size()
+	}
+	return total
+}
@@ -0,0 +1,122 @@
+package container
+
+import (
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStealingPoolBasic(t *testing.T) {
+
+	p := NewStealingPool[int](3,
+		StealingPoolWithVictimSelector(RoundRobinVictims()),
+	)
+	assert.Equal(t, 3, p.Workers())
+	assert.Panics(t, func() { NewStealingPool[int](0) })
+	assert.Panics(t, func() { NewStealingPool[int](-1) })
+
+	assert.ErrorIs(t, p.Push(3, 1), ErrOutOfRange)
+	_, err := p.Pop(-1)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, p.Push(1, i))
+	}
+	assert.NoError(t, p.Push(2, 10))
+	assert.Equal(t, 3, p.Size(1))
+	assert.Equal(t, 4, p.Total())
+
+	// владелец извлекает последнюю добавленную задачу
+	v, err := p.Pop(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+
+	// вор 0 обходит воркеров по кругу (1, 2) и забирает самую старую задачу
+	v, err = p.Steal(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	// у воркера 0 своих задач нет
+	v, err = p.PopOrSteal(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	_, err = p.Steal(2)
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+	_, err = p.Steal(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, p.Total())
+
+}
+func TestStealingPoolLargestVictim(t *testing.T) {
+
+	p := NewStealingPool[int](3,
+		StealingPoolWithVictimSelector(LargestVictim()),
+		StealingPoolWithDequeOptions(DequeWithSizeLimit(2)),
+	)
+	assert.NoError(t, p.Push(1, 1))
+	assert.NoError(t, p.Push(2, 2))
+	assert.NoError(t, p.Push(2, 3))
+	assert.ErrorIs(t, p.Push(2, 4), ErrDequeueFull)
+
+	v, err := p.Steal(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	// самая длинная очередь - у самого вора: свою очередь он пропускает
+	// и крадёт из самой длинной из остальных (у воркеров 1 и 2 по одной задаче)
+	assert.NoError(t, p.Push(0, 5))
+	assert.NoError(t, p.Push(0, 6))
+	v, err = p.Steal(0)
+	assert.NoError(t, err)
+	assert.Contains(t, []int{1, 3}, v)
+
+}
+
+// TestStealingPoolConcurrent - каждая задача выполняется ровно один раз
+func TestStealingPoolConcurrent(t *testing.T) {
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(max(4, runtime.NumCPU())))
+
+	const (
+		workers = 8
+		tasks   = 10_000
+	)
+
+	p := NewStealingPool[int](workers)
+	// все задачи - у первого воркера, остальные вынуждены красть
+	for i := 0; i < tasks; i++ {
+		assert.NoError(t, p.Push(0, i))
+	}
+
+	var done [tasks + tasks/100]atomic.Int32
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for {
+				task, err := p.PopOrSteal(w)
+				if err != nil {
+					if p.Total() == 0 {
+						return
+					}
+					continue
+				}
+				done[task].Add(1)
+				// задачи порождают подзадачи в своей очереди
+				if task%10 == 0 && task < tasks/10 {
+					assert.NoError(t, p.Push(w, tasks+task/10))
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for i := range done {
+		assert.Equal(t, int32(1), done[i].Load(), "task %d", i)
+	}
+
+}
@@ -1,6 +1,7 @@
 package container
 
 import (
+	"iter"
 	"sync"
 	"time"
 )
@@ -64,6 +65,26 @@ func (m *TTLMap[T]) Get(k string) (v T, ok bool) {
 	}
 	return v, ok
 }
+
+// All - возвращает итератор по неустаревшим парам ключ-значение (в произвольном порядке).
+// Перебор выполняется "вживую" под блокировкой на чтение: тело цикла не должно
+// обращаться к map, а запись ждёт завершения перебора. Устаревшие элементы
+// пропускаются, но не удаляются и не считаются прочитанными.
+func (m *TTLMap[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		m.l.RLock()
+		defer m.l.RUnlock()
+		now := time.Now().Unix()
+		for k, it := range m.m {
+			if now-it.created > m.ttl {
+				continue
+			}
+			if !yield(k, it.value) {
+				return
+			}
+		}
+	}
+}
 func (m *TTLMap[T]) Clear() {
 	m.l.Lock()
 	for k, _ := range m.m {
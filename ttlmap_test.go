@@ -0,0 +1,34 @@
+package container
+
+import (
+	"github.com/stretchr/testify/assert"
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestTTLMapIterators(t *testing.T) {
+
+	m := NewTTLMap[int](4, 60)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+	m.m["c"].created = time.Now().Unix() - 120 // устаревший элемент
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, maps.Collect(m.All()))
+	// устаревший элемент пропускается, но не удаляется
+	assert.Equal(t, 3, m.Len())
+
+	var keys []string
+	for k := range m.All() {
+		keys = append(keys, k)
+		break
+	}
+	assert.Len(t, keys, 1)
+	assert.Contains(t, []string{"a", "b"}, keys[0])
+
+	// после прерванного перебора map доступна на запись
+	m.Put("d", 4)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "d": 4}, maps.Collect(m.All()))
+
+}
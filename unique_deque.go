@@ -0,0 +1,294 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+// DuplicatePolicy - поведение UniqueDeque при добавлении уже имеющегося в ней элемента
+type DuplicatePolicy int
+
+const (
+	DuplicateReject  DuplicatePolicy = iota // Отклонить добавление (ErrDuplicate)
+	DuplicateMove                           // Переместить элемент в тот конец очереди, в который он добавляется
+	DuplicateReplace                        // Заменить элемент на прежнем месте
+)
+
+func (p DuplicatePolicy) String() string {
+	switch p {
+	case DuplicateReject:
+		return "reject"
+	case DuplicateMove:
+		return "move"
+	case DuplicateReplace:
+		return "replace"
+	default:
+		return fmt.Sprintf("DuplicatePolicy(%d)", int(p))
+	}
+}
+
+// region - errors
+
+var (
+	ErrDuplicate = errors.New("duplicate element")
+)
+
+// endregion
+
+type uniqueNode[T comparable] struct {
+	item       T
+	prev, next *uniqueNode[T]
+}
+
+// UniqueDeque - двусторонняя очередь без повторяющихся элементов: порядок элементов
+// хранится в двусвязном списке, а принадлежность - в индексе по значению, поэтому
+// Contains, Remove и перемещение дубликата в конец очереди выполняются за O(1).
+// Поведение при добавлении дубликата задаётся DuplicatePolicy. Из опций Deque
+// учитываются ограничение размера, вытеснение, обработчик вытеснения и Observer.
+type UniqueDeque[T comparable] struct {
+	root       uniqueNode[T]        // Ограничитель списка: root.next - голова, root.prev - хвост
+	index      map[T]*uniqueNode[T] // Узлы списка по значению элемента
+	policy     DuplicatePolicy      // Поведение при добавлении дубликата
+	capacity   int                  // Максимальный размер очереди (-1 для безлимитной очереди)
+	preemption bool                 // Вытеснять крайний элемент с противоположной стороны при добавлении нового в заполненную очередь
+	onEvict    func(T, Side)        // Обработчик вытесненных элементов
+	observer   Observer             // Получатель событий очереди
+	mutex      sync.RWMutex
+}
+
+func NewUniqueDeque[T comparable](policy DuplicatePolicy, opts ...DequeOption) *UniqueDeque[T] {
+
+	qc := newQueueConfig(opts...)
+
+	s := &UniqueDeque[T]{
+		index:      make(map[T]*uniqueNode[T]),
+		policy:     policy,
+		capacity:   qc.sizeLimit,
+		preemption: qc.preemption,
+		onEvict:    evictionHandler[T](qc),
+		observer:   qc.observer,
+		mutex:      sync.RWMutex{},
+	}
+	s.root.next = &s.root
+	s.root.prev = &s.root
+
+	return s
+
+}
+
+func (s *UniqueDeque[T]) PushHead(item T) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.push(item, SideHead)
+}
+func (s *UniqueDeque[T]) PushTail(item T) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.push(item, SideTail)
+}
+func (s *UniqueDeque[T]) PopHead() (T, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.pop(SideHead)
+}
+func (s *UniqueDeque[T]) PopTail() (T, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.pop(SideTail)
+}
+func (s *UniqueDeque[T]) PeekHead() (T, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.peek(SideHead)
+}
+func (s *UniqueDeque[T]) PeekTail() (T, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.peek(SideTail)
+}
+
+// Contains - возвращает true, если элемент есть в очереди
+func (s *UniqueDeque[T]) Contains(item T) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	_, ok := s.index[item]
+	return ok
+}
+
+// Remove - удаляет элемент из любого места очереди; false, если его в очереди нет
+func (s *UniqueDeque[T]) Remove(item T) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	node, ok := s.index[item]
+	if !ok {
+		return false
+	}
+	side := s.side(node)
+	s.unlink(node)
+	s.observe(Observer.OnPop, side)
+	return true
+}
+
+func (s *UniqueDeque[T]) Flush() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.observer != nil {
+		for n := len(s.index) - 1; n >= 0; n-- {
+			s.observer.OnPop(DequeEvent{Side: SideHead, Size: n, Capacity: s.capacity})
+		}
+	}
+	s.index = make(map[T]*uniqueNode[T])
+	s.root.next = &s.root
+	s.root.prev = &s.root
+}
+
+// Values - возвращает элементы очереди от головы к хвосту
+func (s *UniqueDeque[T]) Values() []T {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if len(s.index) == 0 {
+		return nil
+	}
+	v := make([]T, 0, len(s.index))
+	for node := s.root.next; node != &s.root; node = node.next {
+		v = append(v, node.item)
+	}
+	return v
+}
+
+// All - возвращает итератор по элементам очереди от головы к хвосту. Перебор
+// выполняется "вживую" под блокировкой на чтение: тело цикла не должно обращаться
+// к очереди, а запись ждёт завершения перебора.
+func (s *UniqueDeque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		for node := s.root.next; node != &s.root; node = node.next {
+			if !yield(node.item) {
+				return
+			}
+		}
+	}
+}
+
+// Size - возвращает количество элементов в очереди
+func (s *UniqueDeque[T]) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.index)
+}
+
+// Capacity - возвращает максимальный размер очереди; -1 для неограниченной очереди
+func (s *UniqueDeque[T]) Capacity() int {
+	return s.capacity
+}
+
+func (s *UniqueDeque[T]) push(item T, side Side) error {
+
+	if node, ok := s.index[item]; ok {
+		switch s.policy {
+		case DuplicateMove:
+			s.detach(node)
+			s.attach(node, side)
+			return nil
+		case DuplicateReplace:
+			node.item = item
+			return nil
+		default:
+			return ErrDuplicate
+		}
+	}
+
+	if s.capacity > 0 && len(s.index) >= s.capacity {
+		if !s.preemption {
+			s.observe(Observer.OnReject, side)
+			return ErrDequeueFull
+		}
+		// вытесняем элемент с противоположной стороны
+		opposite := side.Opposite()
+		evicted := s.end(opposite)
+		s.unlink(evicted)
+		if s.onEvict != nil {
+			s.onEvict(evicted.item, opposite)
+		}
+		s.observe(Observer.OnEvict, opposite)
+	}
+
+	node := &uniqueNode[T]{item: item}
+	s.index[item] = node
+	s.attach(node, side)
+	s.observe(Observer.OnPush, side)
+	return nil
+
+}
+func (s *UniqueDeque[T]) pop(side Side) (T, error) {
+	if len(s.index) == 0 {
+		var empty T
+		return empty, ErrDequeueEmpty
+	}
+	node := s.end(side)
+	s.unlink(node)
+	s.observe(Observer.OnPop, side)
+	return node.item, nil
+}
+func (s *UniqueDeque[T]) peek(side Side) (T, error) {
+	if len(s.index) == 0 {
+		var empty T
+		return empty, ErrDequeueEmpty
+	}
+	return s.end(side).item, nil
+}
+func (s *UniqueDeque[T]) observe(event func(Observer, DequeEvent), side Side) {
+	if s.observer != nil {
+		event(s.observer, DequeEvent{Side: side, Size: len(s.index), Capacity: s.capacity})
+	}
+}
+
+// region - linked list
+
+// end - головной или хвостовой узел непустого списка
+func (s *UniqueDeque[T]) end(side Side) *uniqueNode[T] {
+	if side == SideHead {
+		return s.root.next
+	}
+	return s.root.prev
+}
+
+// side - ближайшая к узлу сторона очереди (для событий Remove); O(1) только для
+// крайних узлов, поэтому остальные считаются хвостовыми
+func (s *UniqueDeque[T]) side(node *uniqueNode[T]) Side {
+	if node == s.root.next {
+		return SideHead
+	}
+	return SideTail
+}
+
+// attach - вставляет узел в голову или хвост списка
+func (s *UniqueDeque[T]) attach(node *uniqueNode[T], side Side) {
+	at := &s.root // вставка после ограничителя - в голову
+	if side == SideTail {
+		at = s.root.prev
+	}
+	node.prev = at
+	node.next = at.next
+	at.next.prev = node
+	at.next = node
+}
+
+// detach - исключает узел из списка, не удаляя его из индекса
+func (s *UniqueDeque[T]) detach(node *uniqueNode[T]) {
+	node.prev.next = node.next
+	node.next.prev = node.prev
+	node.prev = nil
+	node.next = nil
+}
+
+// unlink - удаляет узел из списка и индекса
+func (s *UniqueDeque[T]) unlink(node *uniqueNode[T]) {
+	s.detach(node)
+	delete(s.index, node.item)
+}
+
+// endregion
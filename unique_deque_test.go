@@ -0,0 +1,109 @@
+package container
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestUniqueDequePolicies(t *testing.T) {
+
+	d := NewUniqueDeque[string](DuplicateReject)
+	assert.NoError(t, d.PushTail("a"))
+	assert.NoError(t, d.PushTail("b"))
+	assert.ErrorIs(t, d.PushHead("b"), ErrDuplicate)
+	assert.Equal(t, []string{"a", "b"}, d.Values())
+
+	d = NewUniqueDeque[string](DuplicateMove)
+	assert.NoError(t, d.PushTail("a"))
+	assert.NoError(t, d.PushTail("b"))
+	assert.NoError(t, d.PushTail("c"))
+	assert.NoError(t, d.PushTail("a"))
+	assert.Equal(t, []string{"b", "c", "a"}, d.Values())
+	assert.NoError(t, d.PushHead("c"))
+	assert.Equal(t, []string{"c", "b", "a"}, d.Values())
+	assert.Equal(t, 3, d.Size())
+
+	// 0.0 == -0.0, поэтому -0.0 заменяет 0.0 на прежнем месте
+	f := NewUniqueDeque[float64](DuplicateReplace)
+	assert.NoError(t, f.PushTail(0.0))
+	assert.NoError(t, f.PushTail(1))
+	assert.NoError(t, f.PushTail(math.Copysign(0, -1)))
+	assert.Equal(t, 2, f.Size())
+	v, err := f.PeekHead()
+	assert.NoError(t, err)
+	assert.True(t, math.Signbit(v))
+
+}
+
+func TestUniqueDequeContainsRemove(t *testing.T) {
+
+	d := NewUniqueDeque[int](DuplicateReject)
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, d.PushTail(i))
+	}
+	assert.True(t, d.Contains(3))
+	assert.True(t, d.Remove(3))
+	assert.False(t, d.Remove(3))
+	assert.False(t, d.Contains(3))
+	assert.True(t, d.Remove(1))
+	assert.True(t, d.Remove(5))
+	assert.Equal(t, []int{2, 4}, d.Values())
+
+	// удалённый элемент можно добавить снова
+	assert.NoError(t, d.PushHead(3))
+	v, err := d.PopHead()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v)
+	v, err = d.PopTail()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, v)
+	v, err = d.PeekTail()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	d.Flush()
+	assert.Nil(t, d.Values())
+	assert.False(t, d.Contains(2))
+	_, err = d.PopHead()
+	assert.ErrorIs(t, err, ErrDequeueEmpty)
+
+}
+
+func TestBoundedUniqueDeque(t *testing.T) {
+
+	d := NewUniqueDeque[int](DuplicateMove, DequeWithSizeLimit(3))
+	assert.NoError(t, d.PushTail(1))
+	assert.NoError(t, d.PushTail(2))
+	assert.NoError(t, d.PushTail(3))
+	assert.ErrorIs(t, d.PushTail(4), ErrDequeueFull)
+	assert.NoError(t, d.PushTail(1)) // перемещение не меняет размер
+	assert.Equal(t, []int{2, 3, 1}, d.Values())
+
+	var evicted []int
+	c := NewDequeCounter()
+	d = NewUniqueDeque[int](
+		DuplicateReject,
+		DequeWithSizeLimit(2),
+		DequeWithPreemption(),
+		DequeWithEvictionHandler(func(item int, side Side) {
+			evicted = append(evicted, item)
+		}),
+		DequeWithObserver(c),
+	)
+	assert.NoError(t, d.PushTail(1))
+	assert.NoError(t, d.PushTail(2))
+	assert.NoError(t, d.PushTail(3))
+	assert.NoError(t, d.PushHead(4))
+	assert.Equal(t, []int{4, 2}, d.Values())
+	assert.Equal(t, []int{1, 3}, evicted)
+	assert.False(t, d.Contains(1))
+	assert.False(t, d.Contains(3))
+
+	stats := c.Stats()
+	assert.Equal(t, int64(4), stats.Pushes)
+	assert.Equal(t, int64(2), stats.Evictions)
+	assert.Equal(t, 2, stats.Length)
+	assert.Equal(t, 2, stats.Capacity)
+
+}